@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
 
 	"ganalyzer/internal/analyzer"
 	"ganalyzer/internal/formatter"
@@ -13,17 +16,79 @@ import (
 	"ganalyzer/pkg/types"
 )
 
+const (
+	// Targets accepted by -export-mailmap
+	exportMailmapStdout = "stdout"
+	exportMailmapRepos  = "repos"
+)
+
+// runOptions holds settings that control the run rather than the report layout
+type runOptions struct {
+	jobs           int
+	mailmap        *analyzer.Mailmap
+	fuzzyThreshold float64
+	exportMailmap  string
+	identityRules  *analyzer.IdentityRules
+	bots           *analyzer.BotDetector
+	revisions      string
+	repoRevisions  map[string]string
+	paths          *analyzer.PathFilter
+	languages      *analyzer.LanguageTable
+}
+
 func main() {
 	var config formatter.Config
+	var opts runOptions
 	var showVersion bool
+	var showSchema bool
+	var since, until string
+	var mailmapPath string
+	var rulesPath string
+	var botPatterns string
+	var revisionsPath string
+	var include, exclude string
+	var defaultExcludes, gitAttributes bool
+	var languages, languagesPath string
 
 	flag.StringVar(&config.Directory, "dir", ".", "Directory to scan for Git repositories")
-	flag.StringVar(&config.OutputFormat, "format", "table", "Output format: table, json, csv")
+	flag.StringVar(&config.OutputFormat, "format", "table", "Output format: table, json, csv, matrix, matrix-csv")
 	flag.IntVar(&config.TopN, "top", 0, "Show only top N contributors (0 = all)")
-	flag.StringVar(&config.SortBy, "sort", "commits", "Sort by: commits, lines, combined")
+	flag.StringVar(&config.SortBy, "sort", "commits", "Sort by: commits, lines, combined, ownership, binary, first-commit, last-commit, active-days, tenure, longest-gap")
 	flag.BoolVar(&config.NormalizeNames, "normalize", false, "Normalize contributor names (remove diacritics, punctuation, case differences)")
 	flag.BoolVar(&config.ShowAliases, "aliases", false, "Show contributor aliases when normalization is enabled")
+	flag.StringVar(&config.MatrixMetric, "matrix-metric", formatter.MatrixCommits, "Cell value of the matrix formats: commits, lines, share")
+	flag.BoolVar(&config.PerRepository, "per-repo", false, "Also report the top contributors of each repository (CSV: one row per repository and contributor)")
+	flag.BoolVar(&config.Ownership, "ownership", false, "Blame every text file at HEAD and report the surviving lines each contributor owns")
+	flag.BoolVar(&config.Concentration, "bus-factor", false, "Add a repository summary with bus factor, Gini coefficient and top-1 share")
+	flag.IntVar(&config.RiskBusFactor, "risk-bus-factor", 1, "Flag repositories whose bus factor is at most this value")
+	flag.Float64Var(&config.RiskTopShare, "risk-top-share", 0, "Flag repositories whose top contributor holds at least this share in (0, 1]; 0 disables")
+	flag.Float64Var(&config.RiskGini, "risk-gini", 0, "Flag repositories whose Gini coefficient is at least this value in (0, 1]; 0 disables")
+	flag.IntVar(&config.ActiveWithin, "active-within", 0, "Only report contributors who committed within the last N days (0 = all)")
+	flag.StringVar(&config.Interval, "interval", "", "Collect activity per week, month or quarter: adds a trend column, a JSON series and long-form CSV")
+	flag.StringVar(&include, "include", "", "Comma-separated globs of the only paths counted in line statistics")
+	flag.StringVar(&exclude, "exclude", "", "Comma-separated globs of paths left out of line statistics")
+	flag.BoolVar(&defaultExcludes, "default-excludes", false, "Leave lockfiles, vendored dependencies, generated protobufs and minified assets out of line statistics")
+	flag.BoolVar(&gitAttributes, "gitattributes", false, "Leave files marked linguist-generated or linguist-vendored in .gitattributes out of line statistics")
+	flag.BoolVar(&config.Binary, "binary", false, "Report the number of binary file changes per contributor")
+	flag.BoolVar(&config.BinaryBytes, "binary-bytes", false, "Also report by how many bytes changed binary files grew and shrank (reads every changed blob's size)")
+	flag.StringVar(&languages, "languages", "", "Comma-separated languages to add line columns for in table and CSV, or all")
+	flag.StringVar(&languagesPath, "language-map", "", "JSON file with extra file extension and file name to language mappings")
+	flag.StringVar(&config.GroupBy, "group-by", analyzer.GroupByName, "Identify contributors by: name, email")
+	flag.StringVar(&since, "since", "", "Only count commits after this date (e.g. 2024-01-01, \"90 days ago\")")
+	flag.StringVar(&until, "until", "", "Only count commits before this date (e.g. 2024-03-31, yesterday)")
+	flag.StringVar(&mailmapPath, "mailmap", "", "Central mailmap file applied to all repositories in addition to their own .mailmap")
+	flag.StringVar(&rulesPath, "identity-rules", "", "JSON file with manual merge, never-merge and display name rules")
+	flag.Float64Var(&opts.fuzzyThreshold, "fuzzy", 0, "Merge similar identities (initials, typos, shared emails) with confidence >= threshold in (0, 1]; 0 disables")
+	flag.StringVar(&opts.exportMailmap, "export-mailmap", "", "Generate a .mailmap from the merged identities: stdout, repos (merge into each repository)")
+	flag.StringVar(&config.BotMode, "bots", "", "Detect bot accounts and exclude, flag or separate them: exclude, flag, separate")
+	flag.StringVar(&botPatterns, "bot-patterns", "", "Comma-separated extra name/email substrings identifying bots")
+	flag.StringVar(&config.CoAuthors, "co-authors", "", "Credit Co-authored-by trailers: full, split, column")
+	flag.StringVar(&opts.revisions, "revisions", analyzer.RevisionsAll, "History to analyze: all, default, mainline, a ref glob (refs/heads/release/*) or a revision range (v1.0..v2.0)")
+	flag.StringVar(&revisionsPath, "revisions-file", "", "JSON file mapping repository names or paths to -revisions values")
+	flag.StringVar(&config.Dedupe, "dedupe", "", "Collapse duplicated commits such as cherry-picks: patch-id, cherry-pick")
+	flag.IntVar(&opts.jobs, "jobs", runtime.NumCPU(), "Number of repositories to analyze in parallel")
 	flag.BoolVar(&showVersion, "version", false, "Show version information")
+	flag.BoolVar(&showSchema, "json-schema", false, "Print the JSON Schema of the json output format and exit")
 	flag.Parse()
 
 	if showVersion {
@@ -31,9 +96,111 @@ func main() {
 		os.Exit(0)
 	}
 
+	if showSchema {
+		schema, err := formatter.JSONSchema()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		os.Stdout.Write(schema)
+		os.Exit(0)
+	}
+
 	// Validate flag dependencies
-	if config.ShowAliases && !config.NormalizeNames {
-		fmt.Fprintf(os.Stderr, "Warning: -aliases flag requires -normalize flag to be effective\n")
+	if config.GroupBy != analyzer.GroupByName && config.GroupBy != analyzer.GroupByEmail {
+		fmt.Fprintf(os.Stderr, "Error: unsupported -group-by value: %s\n", config.GroupBy)
+		os.Exit(1)
+	}
+
+	switch config.MatrixMetric {
+	case formatter.MatrixCommits, formatter.MatrixLines, formatter.MatrixShare:
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unsupported -matrix-metric value: %s\n", config.MatrixMetric)
+		os.Exit(1)
+	}
+
+	switch config.Interval {
+	case "", types.IntervalWeek, types.IntervalMonth, types.IntervalQuarter:
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unsupported -interval value: %s\n", config.Interval)
+		os.Exit(1)
+	}
+
+	if config.ShowAliases && !config.NormalizeNames && config.GroupBy != analyzer.GroupByEmail {
+		fmt.Fprintf(os.Stderr, "Warning: -aliases flag requires -normalize or -group-by email to be effective\n")
+	}
+
+	if opts.fuzzyThreshold < 0 || opts.fuzzyThreshold > 1 {
+		fmt.Fprintf(os.Stderr, "Error: -fuzzy must be between 0 and 1, got %g\n", opts.fuzzyThreshold)
+		os.Exit(1)
+	}
+
+	switch config.BotMode {
+	case "":
+		if botPatterns != "" {
+			fmt.Fprintf(os.Stderr, "Warning: -bot-patterns flag requires -bots to be effective\n")
+		}
+	case analyzer.BotsExclude, analyzer.BotsFlag, analyzer.BotsSeparate:
+		opts.bots = analyzer.NewBotDetector(strings.Split(botPatterns, ","))
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unsupported -bots value: %s\n", config.BotMode)
+		os.Exit(1)
+	}
+
+	switch config.CoAuthors {
+	case "", analyzer.CoAuthorsFull, analyzer.CoAuthorsSplit, analyzer.CoAuthorsColumn:
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unsupported -co-authors value: %s\n", config.CoAuthors)
+		os.Exit(1)
+	}
+
+	switch config.Dedupe {
+	case "", analyzer.DedupePatchID, analyzer.DedupeCherryPick:
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unsupported -dedupe value: %s\n", config.Dedupe)
+		os.Exit(1)
+	}
+
+	if config.RiskBusFactor < 0 {
+		fmt.Fprintf(os.Stderr, "Error: -risk-bus-factor must not be negative, got %d\n", config.RiskBusFactor)
+		os.Exit(1)
+	}
+
+	if config.RiskTopShare < 0 || config.RiskTopShare > 1 {
+		fmt.Fprintf(os.Stderr, "Error: -risk-top-share must be between 0 and 1, got %g\n", config.RiskTopShare)
+		os.Exit(1)
+	}
+
+	if config.RiskGini < 0 || config.RiskGini > 1 {
+		fmt.Fprintf(os.Stderr, "Error: -risk-gini must be between 0 and 1, got %g\n", config.RiskGini)
+		os.Exit(1)
+	}
+
+	if config.ActiveWithin < 0 {
+		fmt.Fprintf(os.Stderr, "Error: -active-within must not be negative, got %d\n", config.ActiveWithin)
+		os.Exit(1)
+	}
+
+	if opts.jobs < 1 {
+		fmt.Fprintf(os.Stderr, "Error: -jobs must be at least 1, got %d\n", opts.jobs)
+		os.Exit(1)
+	}
+
+	if opts.exportMailmap != "" && opts.exportMailmap != exportMailmapStdout && opts.exportMailmap != exportMailmapRepos {
+		fmt.Fprintf(os.Stderr, "Error: unsupported -export-mailmap value: %s\n", opts.exportMailmap)
+		os.Exit(1)
+	}
+
+	paths, err := analyzer.NewPathFilter(strings.Split(include, ","), strings.Split(exclude, ","), defaultExcludes, gitAttributes)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid -include or -exclude: %v\n", err)
+		os.Exit(1)
+	}
+	opts.paths = paths
+
+	if err := resolveTimeWindow(&config, since, until, time.Now()); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
 	}
 
 	absDir, err := filepath.Abs(config.Directory)
@@ -43,15 +210,104 @@ func main() {
 	}
 	config.Directory = absDir
 
-	if err := run(config); err != nil {
+	if mailmapPath != "" {
+		if opts.mailmap, err = analyzer.LoadMailmap(mailmapPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if rulesPath != "" {
+		if opts.identityRules, err = analyzer.LoadIdentityRules(rulesPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if languagesPath != "" {
+		if opts.languages, err = analyzer.LoadLanguageTable(languagesPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	for _, language := range strings.Split(languages, ",") {
+		if language = strings.TrimSpace(language); language != "" {
+			config.Languages = append(config.Languages, language)
+		}
+	}
+
+	if revisionsPath != "" {
+		if opts.repoRevisions, err = analyzer.LoadRevisionSpecs(revisionsPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	config.GeneratedAt = time.Now()
+	config.Options = reportOptions()
+
+	if err := run(config, opts); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
-func run(config formatter.Config) error {
+// reportOptions returns the value of every option affecting the report, by
+// flag name, for the metadata of the JSON output
+func reportOptions() map[string]string {
+	options := make(map[string]string)
+	flag.VisitAll(func(f *flag.Flag) {
+		switch f.Name {
+		case "version", "json-schema", "jobs":
+			return
+		}
+		options[f.Name] = f.Value.String()
+	})
+	return options
+}
+
+// resolveTimeWindow parses the -since/-until values once, so every repository
+// is analyzed against the same absolute window
+func resolveTimeWindow(config *formatter.Config, since, until string, now time.Time) error {
+	var err error
+	if since != "" {
+		if config.Since, err = analyzer.ParseSince(since, now); err != nil {
+			return fmt.Errorf("invalid -since value: %w", err)
+		}
+	}
+	if until != "" {
+		if config.Until, err = analyzer.ParseUntil(until, now); err != nil {
+			return fmt.Errorf("invalid -until value: %w", err)
+		}
+	}
+	if !config.Since.IsZero() && !config.Until.IsZero() && config.Until.Before(config.Since) {
+		return fmt.Errorf("-until (%s) is before -since (%s)", config.Until.Format(time.RFC3339), config.Since.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func run(config formatter.Config, opts runOptions) error {
 	repoScanner := scanner.NewScanner()
-	repoAnalyzer := analyzer.NewAnalyzerWithNormalization(config.NormalizeNames)
+	repoAnalyzer := analyzer.NewAnalyzerWithOptions(analyzer.Options{
+		Normalize:           config.NormalizeNames,
+		GroupBy:             config.GroupBy,
+		Since:               config.Since,
+		Until:               config.Until,
+		Mailmap:             opts.mailmap,
+		FuzzyThreshold:      opts.fuzzyThreshold,
+		Rules:               opts.identityRules,
+		Bots:                opts.bots,
+		CoAuthors:           config.CoAuthors,
+		Dedupe:              config.Dedupe,
+		Revisions:           opts.revisions,
+		RepositoryRevisions: opts.repoRevisions,
+		Interval:            config.Interval,
+		Ownership:           config.Ownership,
+		Concentration:       config.Concentration,
+		Paths:               opts.paths,
+		Languages:           opts.languages,
+		BinaryBytes:         config.BinaryBytes,
+	})
 	repoFormatter := formatter.NewFormatter()
 	globalStats := types.NewGlobalStats()
 
@@ -66,19 +322,67 @@ func run(config formatter.Config) error {
 		return nil
 	}
 
-	fmt.Fprintf(os.Stderr, "Found %d repositories, analyzing...\n", len(repos))
+	fmt.Fprintf(os.Stderr, "Found %d repositories, analyzing with %d workers...\n", len(repos), opts.jobs)
 
-	for i, repoPath := range repos {
-		fmt.Fprintf(os.Stderr, "Analyzing repository %d/%d: %s\n", i+1, len(repos), repoPath)
+	// Merge in scan order so output does not depend on worker scheduling, and
+	// as soon as possible so each repository's commit ledger can be released
+	repoAnalyzer.StreamRepositories(repos, opts.jobs, func(done, total int, result analyzer.Result) {
+		fmt.Fprintf(os.Stderr, "Analyzed repository %d/%d: %s\n", done, total, result.Path)
+	}, func(result analyzer.Result) {
+		if result.Err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to analyze %s: %v\n", result.Path, result.Err)
+			return
+		}
+
+		globalStats.AddRepository(result.Repository)
+	})
+
+	repoAnalyzer.ResolveIdentities(globalStats)
+
+	if config.BotMode == analyzer.BotsExclude {
+		fmt.Fprintf(os.Stderr, "Excluded %d bot accounts\n", globalStats.RemoveBots())
+	}
+
+	if config.ActiveWithin > 0 {
+		cutoff := time.Now().AddDate(0, 0, -config.ActiveWithin)
+		fmt.Fprintf(os.Stderr, "Excluded %d contributors without commits in the last %d days\n", globalStats.RemoveInactive(cutoff), config.ActiveWithin)
+	}
 
-		repo, err := repoAnalyzer.AnalyzeRepository(repoPath)
+	if opts.mailmap != nil {
+		reportMailmapHits(opts.mailmap)
+	}
+
+	switch opts.exportMailmap {
+	case exportMailmapStdout:
+		entries := analyzer.GenerateMailmap(globalStats.GetSortedContributors(config.SortBy, 0))
+		return analyzer.WriteMailmap(os.Stdout, entries)
+	case exportMailmapRepos:
+		writeRepositoryMailmaps(globalStats)
+	}
+
+	return repoFormatter.Format(globalStats, config, os.Stdout)
+}
+
+// writeRepositoryMailmaps merges generated entries into each analyzed repository's .mailmap
+func writeRepositoryMailmaps(globalStats *types.GlobalStats) {
+	for _, repo := range globalStats.Repositories {
+		path := filepath.Join(repo.Path, ".mailmap")
+		added, err := analyzer.MergeMailmapFile(path, analyzer.RepositoryMailmap(repo, globalStats))
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to analyze %s: %v\n", repoPath, err)
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
 			continue
 		}
-
-		globalStats.AddRepository(repo)
+		if added > 0 {
+			fmt.Fprintf(os.Stderr, "Added %d entries to %s\n", added, path)
+		}
 	}
+}
 
-	return repoFormatter.Format(globalStats, config, os.Stdout)
+// reportMailmapHits lists the central mailmap entries that rewrote at least one commit identity
+func reportMailmapHits(mailmap *analyzer.Mailmap) {
+	hits := mailmap.Hits()
+	fmt.Fprintf(os.Stderr, "Mailmap: %d of %d entries applied\n", len(hits), mailmap.Len())
+	for _, hit := range hits {
+		fmt.Fprintf(os.Stderr, "  line %d: %s (%d resolutions)\n", hit.Entry.Line, hit.Entry, hit.Count)
+	}
 }
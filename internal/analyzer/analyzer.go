@@ -1,42 +1,150 @@
 package analyzer
 
 import (
-	"bufio"
+	"bytes"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
-	"regexp"
-	"strconv"
+	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"ganalyzer/pkg/types"
 )
 
 const (
-	// Expected number of regex matches for commit parsing
-	expectedCommitMatches = 3
-	// Minimum number of parts expected in git log output
-	minLogParts = 3
+	// GroupByName keys contributors by author name (normalized when enabled)
+	GroupByName = "name"
+	// GroupByEmail keys contributors by author email, falling back to the name when it is missing
+	GroupByEmail = "email"
+
+	// Ways of crediting Co-authored-by trailers, selected with Options.CoAuthors
+	CoAuthorsFull   = "full"
+	CoAuthorsSplit  = "split"
+	CoAuthorsColumn = "column"
 )
 
+// Options configures how an Analyzer inspects repositories
+type Options struct {
+	// Normalize groups contributor name variants under one key
+	Normalize bool
+	// GroupBy selects the contributor identity: GroupByName (default) or GroupByEmail
+	GroupBy string
+	// Since and Until bound the analyzed history; zero values mean unbounded
+	Since time.Time
+	Until time.Time
+	// Mailmap is applied to every identity after the repository's own .mailmap
+	// and before name normalization; nil disables it
+	Mailmap *Mailmap
+	// FuzzyThreshold enables fuzzy identity clustering in ResolveIdentities:
+	// contributors whose similarity reaches it (0-1] are merged; 0 disables it
+	FuzzyThreshold float64
+	// Rules are manual merge and never-merge overrides applied on top of
+	// normalization and every identity stage; nil disables them
+	Rules *IdentityRules
+	// Bots detects automation accounts during identity resolution; nil disables detection
+	Bots *BotDetector
+	// CoAuthors credits Co-authored-by trailers: CoAuthorsFull gives every
+	// co-author the commit and all its lines, CoAuthorsSplit divides the lines
+	// evenly between author and co-authors, and CoAuthorsColumn only counts
+	// co-authored commits. "" ignores trailers.
+	CoAuthors string
+	// Dedupe collapses logically identical commits within a repository:
+	// DedupePatchID or DedupeCherryPick; "" counts every commit
+	Dedupe string
+	// Revisions selects the analyzed history of every repository: RevisionsAll
+	// (default), RevisionsDefault, RevisionsMainline, a ref glob or revisions
+	// and ranges passed to git
+	Revisions string
+	// RepositoryRevisions overrides Revisions for repositories whose path,
+	// path suffix or name matches a key
+	RepositoryRevisions map[string]string
+	// Ownership blames every text file at HEAD and credits contributors with
+	// the lines they last changed
+	Ownership bool
+	// Concentration records per file how much each contributor wrote, from
+	// blamed lines with Ownership and from lines changed otherwise, so
+	// repositories can report bus factor and knowledge concentration
+	Concentration bool
+	// Paths selects the files whose lines count towards line statistics,
+	// ownership and concentration; nil counts every file
+	Paths *PathFilter
+	// Languages maps changed files to languages; nil uses the built-in table
+	Languages *LanguageTable
+	// BinaryBytes measures by how many bytes every changed binary file grew
+	// or shrank, on top of counting the changes
+	BinaryBytes bool
+	// Interval collects an activity series per contributor by author date:
+	// types.IntervalWeek, IntervalMonth or IntervalQuarter; "" disables it
+	Interval string
+}
+
 // Analyzer analyzes Git repositories to extract contributor statistics
 type Analyzer struct {
-	normalizer *NameNormalizer
-	normalize  bool
+	normalizer          *NameNormalizer
+	normalize           bool
+	groupBy             string
+	since               time.Time
+	until               time.Time
+	mailmap             *Mailmap
+	fuzzyThreshold      float64
+	rules               *IdentityRules
+	bots                *BotDetector
+	coAuthors           string
+	dedupe              string
+	revisions           string
+	repositoryRevisions map[string]string
+	interval            string
+	ownership           bool
+	concentration       bool
+	paths               *PathFilter
+	languages           *LanguageTable
+	binaryBytes         bool
 }
 
 // NewAnalyzer creates a new Analyzer instance without normalization
 func NewAnalyzer() *Analyzer {
-	return &Analyzer{
-		normalizer: NewNameNormalizer(),
-		normalize:  false,
-	}
+	return NewAnalyzerWithOptions(Options{})
 }
 
 // NewAnalyzerWithNormalization creates a new Analyzer with optional name normalization
 func NewAnalyzerWithNormalization(normalize bool) *Analyzer {
+	return NewAnalyzerWithOptions(Options{Normalize: normalize})
+}
+
+// NewAnalyzerWithOptions creates a new Analyzer configured by opts
+func NewAnalyzerWithOptions(opts Options) *Analyzer {
+	groupBy := opts.GroupBy
+	if groupBy == "" {
+		groupBy = GroupByName
+	}
+	languages := opts.Languages
+	if languages == nil {
+		languages = NewLanguageTable()
+	}
+
 	return &Analyzer{
-		normalizer: NewNameNormalizer(),
-		normalize:  normalize,
+		normalizer:          NewNameNormalizer(),
+		normalize:           opts.Normalize,
+		groupBy:             groupBy,
+		since:               opts.Since,
+		until:               opts.Until,
+		mailmap:             opts.Mailmap,
+		fuzzyThreshold:      opts.FuzzyThreshold,
+		rules:               opts.Rules,
+		bots:                opts.Bots,
+		coAuthors:           opts.CoAuthors,
+		dedupe:              opts.Dedupe,
+		revisions:           opts.Revisions,
+		repositoryRevisions: opts.RepositoryRevisions,
+		interval:            opts.Interval,
+		ownership:           opts.Ownership,
+		concentration:       opts.Concentration,
+		paths:               opts.Paths,
+		languages:           languages,
+		binaryBytes:         opts.BinaryBytes,
 	}
 }
 
@@ -44,142 +152,369 @@ func NewAnalyzerWithNormalization(normalize bool) *Analyzer {
 func (a *Analyzer) AnalyzeRepository(repoPath string) (*types.Repository, error) {
 	repo := types.NewRepository(repoPath)
 
-	if err := a.analyzeCommits(repo); err != nil {
-		return nil, fmt.Errorf("failed to analyze commits in %s: %w", repoPath, err)
+	repo.Revision = a.revisionSpec(repoPath)
+	selection, err := resolveRevisions(repoPath, repo.Revision)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve revisions %q in %s: %w", repo.Revision, repoPath, err)
+	}
+	repo.Refs = selection.refs
+	repo.Head, _ = resolveCommit(repoPath, "HEAD")
+
+	filter := a.paths.forRepository(repoPath, repo.Head)
+	if selection.args != nil {
+		revisions := a.revisionArgs(selection.args)
+		if err := a.analyzeHistory(repo, revisions, filter); err != nil {
+			return nil, fmt.Errorf("failed to analyze history in %s: %w", repoPath, err)
+		}
+
+		if err := a.collapseDuplicates(repo, revisions); err != nil {
+			return nil, fmt.Errorf("failed to deduplicate commits in %s: %w", repoPath, err)
+		}
+	}
+
+	if a.ownership && repo.Head != "" {
+		if err := a.blameOwnership(repo, filter); err != nil {
+			return nil, fmt.Errorf("failed to blame files in %s: %w", repoPath, err)
+		}
+	} else if a.concentration {
+		if err := keepFilesAt(repo, repo.Head); err != nil {
+			return nil, fmt.Errorf("failed to list files in %s: %w", repoPath, err)
+		}
 	}
 
-	if err := a.analyzeLineChanges(repo); err != nil {
-		return nil, fmt.Errorf("failed to analyze line changes in %s: %w", repoPath, err)
+	roots, err := rootCommits(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list root commits in %s: %w", repoPath, err)
 	}
+	repo.RootCommits = roots
 
 	return repo, nil
 }
 
-func (a *Analyzer) getContributorKey(name string) string {
-	if a.normalize {
-		return a.normalizer.NormalizeName(name)
+// rootCommits returns the parentless commits reachable from any ref. They
+// identify the project regardless of the analyzed time window, so forks and
+// clones can be recognized.
+func rootCommits(repoPath string) ([]string, error) {
+	output, err := exec.Command("git", "-C", repoPath, "rev-list", "--max-parents=0", "--all").Output()
+	if err != nil {
+		return nil, fmt.Errorf("git rev-list failed: %w", err)
 	}
-	return name
+	return strings.Fields(string(output)), nil
 }
 
-func (a *Analyzer) analyzeCommits(repo *types.Repository) error {
-	cmd := exec.Command("git", "-C", repo.Path, "shortlog", "-sn", "--all")
-	output, err := cmd.Output()
-	if err != nil {
-		return fmt.Errorf("git shortlog failed: %w", err)
+// revisionArgs returns the revision selection shared by every git invocation,
+// so commit counts and line changes always cover the same history. The
+// trailing "--" keeps revisions from being mistaken for paths.
+//
+// The time window is not passed on: git compares --since and --until with
+// committer dates, and --since stops walking at the first older commit, so
+// commits behind a rebased or clock-skewed one would be missed. recordCommit
+// applies the window to author dates instead, like tenure and activity.
+func (a *Analyzer) revisionArgs(selection []string) []string {
+	return append(append([]string(nil), selection...), "--")
+}
+
+// inWindow reports whether a commit authored at t falls within the analyzed
+// time window; commits without a known date are kept
+func (a *Analyzer) inWindow(t time.Time) bool {
+	if t.IsZero() {
+		return true
 	}
+	return (a.since.IsZero() || !t.Before(a.since)) && (a.until.IsZero() || !t.After(a.until))
+}
 
-	scanner := bufio.NewScanner(strings.NewReader(string(output)))
-	commitCountRegex := regexp.MustCompile(`^\s*(\d+)\s+(.+)$`)
+// getContributorKey returns the key an identity is grouped under. A matching
+// merge rule overrides grouping entirely, and identities listed in a
+// never-merge rule get a key of their own.
+func (a *Analyzer) getContributorKey(name, email string, rule *MergeRule) string {
+	if rule != nil {
+		return ruleKeyPrefix + rule.Name
+	}
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		matches := commitCountRegex.FindStringSubmatch(line)
-		if len(matches) != expectedCommitMatches {
-			continue
-		}
+	key := name
+	if a.groupBy == GroupByEmail && email != "" {
+		key = strings.ToLower(email)
+	} else if a.normalize {
+		key = a.normalizer.NormalizeName(name)
+	}
 
-		count, err := strconv.Atoi(matches[1])
-		if err != nil {
-			continue
-		}
+	if split := a.rules.splitKey(name, email); split != "" {
+		key += splitKeySeparator + split
+	}
+	return key
+}
 
-		authorName := strings.TrimSpace(matches[2])
-		contributorKey := a.getContributorKey(authorName)
+// mergesNames reports whether different author names can share one contributor key
+func (a *Analyzer) mergesNames() bool {
+	return a.normalize || a.groupBy == GroupByEmail
+}
 
-		if _, exists := repo.Contributors[contributorKey]; !exists {
-			repo.Contributors[contributorKey] = &types.ContributorStats{
-				Name:    authorName, // Keep original name for display
-				Aliases: make([]string, 0),
-			}
+// registerContributor records the identity on its contributor entry, creating
+// the entry on first sight, and returns the entry's key
+func (a *Analyzer) registerContributor(repo *types.Repository, authorName, authorEmail string) string {
+	rule := a.rules.match(authorName, authorEmail)
+	contributorKey := a.getContributorKey(authorName, authorEmail, rule)
+
+	stats, exists := repo.Contributors[contributorKey]
+	if !exists {
+		displayName := authorName // Keep original name for display
+		if rule != nil {
+			displayName = rule.Name
 		}
-
-		// Add this authorName as an alias if normalization is enabled and it's different from the stored name
-		if a.normalize && authorName != repo.Contributors[contributorKey].Name {
-			aliases := repo.Contributors[contributorKey].Aliases
-			found := false
-			for _, alias := range aliases {
-				if alias == authorName {
-					found = true
-					break
-				}
-			}
-			if !found {
-				repo.Contributors[contributorKey].Aliases = append(repo.Contributors[contributorKey].Aliases, authorName)
-			}
+		stats = &types.ContributorStats{
+			Name:    displayName,
+			Aliases: make([]string, 0),
 		}
+		repo.Contributors[contributorKey] = stats
+	}
 
-		repo.Contributors[contributorKey].CommitCount += count
+	// Add this authorName as an alias if names are merged and it's different from the stored name
+	if a.mergesNames() || rule != nil {
+		stats.AddAlias(authorName)
 	}
+	stats.AddEmail(authorEmail)
+	stats.AddIdentity(authorName, authorEmail)
 
-	return scanner.Err()
+	return contributorKey
 }
 
-func (a *Analyzer) analyzeLineChanges(repo *types.Repository) error {
-	cmd := exec.Command("git", "-C", repo.Path, "log", "--all", "--format=%aN", "--numstat")
-	output, err := cmd.Output()
+// analyzeHistory streams the repository log once, collecting commit counts
+// and line changes for every author in a single pass
+func (a *Analyzer) analyzeHistory(repo *types.Repository, revisions []string, filter *repositoryFilter) error {
+	args := append([]string{"-C", repo.Path}, logArgs()...)
+	args = append(args, revisions...)
+	cmd := exec.Command("git", args...)
+
+	local := a.repositoryMailmap(repo.Path)
+
+	var sizer *blobSizer
+	if a.binaryBytes {
+		var err error
+		if sizer, err = newBlobSizer(repo.Path); err != nil {
+			return err
+		}
+		defer sizer.Close()
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return fmt.Errorf("git log failed: %w", err)
 	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("git log failed: %w", err)
+	}
 
-	lines := strings.Split(string(output), "\n")
-	var currentAuthor string
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
+	parseErr := parseLog(stdout, func(commit *commitRecord) error {
+		return a.recordCommit(repo, commit, local, filter, sizer)
+	})
+	if parseErr != nil {
+		// Drain the pipe so git can exit before we wait on it
+		_, _ = io.Copy(io.Discard, stdout)
+	}
 
-		if !strings.Contains(line, "\t") {
-			currentAuthor = line
-			continue
-		}
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("git log failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return parseErr
+}
 
-		if currentAuthor == "" {
-			continue
-		}
+// repositoryMailmap loads the repository's own .mailmap for identities git
+// does not map itself, such as co-author trailers. It returns nil when
+// trailers are ignored or the file is missing or malformed, since git also
+// tolerates a broken .mailmap.
+func (a *Analyzer) repositoryMailmap(repoPath string) *Mailmap {
+	if a.coAuthors == "" {
+		return nil
+	}
+	path := filepath.Join(repoPath, ".mailmap")
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	mailmap, err := LoadMailmap(path)
+	if err != nil {
+		return nil
+	}
+	return mailmap
+}
 
-		parts := strings.Split(line, "\t")
-		if len(parts) < minLogParts {
-			continue
-		}
+// recordCommit credits the commit's author and co-authors; sizer measures
+// binary files when byte sizes are requested and is nil otherwise
+func (a *Analyzer) recordCommit(repo *types.Repository, commit *commitRecord, local *Mailmap, filter *repositoryFilter, sizer *blobSizer) error {
+	if commit.AuthorName == "" || !a.inWindow(commit.AuthorTime) {
+		return nil
+	}
 
-		added, err1 := strconv.Atoi(parts[0])
-		deleted, err2 := strconv.Atoi(parts[1])
+	// git has already applied the repository's .mailmap through %aN/%aE
+	authorName, authorEmail := a.mailmap.Resolve(commit.AuthorName, commit.AuthorEmail)
+	author := types.CommitCredit{Key: a.registerContributor(repo, authorName, authorEmail), Commits: 1}
+	if a.bots.automatedSubject(commit.Subject) {
+		author.Automated = 1
+	}
 
-		if err1 != nil || err2 != nil {
+	files := make([]fileStat, 0, len(commit.Files))
+	languages := make(map[string]types.LanguageLines)
+	added, deleted := 0, 0
+	binary := types.CommitCredit{}
+	var filtered map[string]int
+	for _, file := range commit.Files {
+		if excludedBy := filter.excludedBy(file.Path); excludedBy != "" {
+			if file.Added+file.Deleted > 0 {
+				if filtered == nil {
+					filtered = make(map[string]int)
+				}
+				filtered[excludedBy] += file.Added + file.Deleted
+			}
 			continue
 		}
-
-		contributorKey := a.getContributorKey(currentAuthor)
-		if _, exists := repo.Contributors[contributorKey]; !exists {
-			repo.Contributors[contributorKey] = &types.ContributorStats{
-				Name:    currentAuthor, // Keep original name for display
-				Aliases: make([]string, 0),
+		if file.Binary {
+			binary.BinaryFiles++
+			if sizer != nil {
+				grown, shrunk, err := sizer.change(commit.Hash, file)
+				if err != nil {
+					return err
+				}
+				binary.BinaryBytesAdded += grown
+				binary.BinaryBytesDeleted += shrunk
 			}
+			continue
+		}
+		files = append(files, file)
+		added += file.Added
+		deleted += file.Deleted
+		if file.Added+file.Deleted > 0 {
+			language := a.languages.Language(file.Path)
+			lines := languages[language]
+			lines.LinesAdded += file.Added
+			lines.LinesDeleted += file.Deleted
+			languages[language] = lines
 		}
+	}
 
-		// Add this currentAuthor as an alias if normalization is enabled and it's different from the stored name
-		if a.normalize && currentAuthor != repo.Contributors[contributorKey].Name {
-			aliases := repo.Contributors[contributorKey].Aliases
-			found := false
-			for _, alias := range aliases {
-				if alias == currentAuthor {
-					found = true
-					break
-				}
+	coAuthors := a.coAuthorsOf(repo, commit, author.Key, local)
+	credits := make([]types.CommitCredit, 0, len(coAuthors)+1)
+	switch a.coAuthors {
+	case CoAuthorsFull:
+		full := languageCredits(languages)
+		for _, key := range coAuthors {
+			credits = append(credits, types.CommitCredit{
+				Key: key, Commits: 1, CoAuthored: 1, LinesAdded: added, LinesDeleted: deleted, Languages: full,
+				BinaryFiles: binary.BinaryFiles, BinaryBytesAdded: binary.BinaryBytesAdded, BinaryBytesDeleted: binary.BinaryBytesDeleted,
+			})
+		}
+	case CoAuthorsSplit:
+		// Every participant gets an equal share of each language; the author
+		// keeps the remainder
+		participants := len(coAuthors) + 1
+		shares := make(map[string]types.LanguageLines, len(languages))
+		remainder := make(map[string]types.LanguageLines, len(languages))
+		shareAdded, shareDeleted := 0, 0
+		for language, lines := range languages {
+			share := types.LanguageLines{LinesAdded: lines.LinesAdded / participants, LinesDeleted: lines.LinesDeleted / participants}
+			if share != (types.LanguageLines{}) {
+				shares[language] = share
 			}
-			if !found {
-				repo.Contributors[contributorKey].Aliases = append(repo.Contributors[contributorKey].Aliases, currentAuthor)
+			remainder[language] = types.LanguageLines{
+				LinesAdded:   lines.LinesAdded - share.LinesAdded*len(coAuthors),
+				LinesDeleted: lines.LinesDeleted - share.LinesDeleted*len(coAuthors),
 			}
+			shareAdded += share.LinesAdded
+			shareDeleted += share.LinesDeleted
+		}
+		binaryShare := types.CommitCredit{
+			BinaryFiles:        binary.BinaryFiles / participants,
+			BinaryBytesAdded:   binary.BinaryBytesAdded / participants,
+			BinaryBytesDeleted: binary.BinaryBytesDeleted / participants,
+		}
+		shared := languageCredits(shares)
+		for _, key := range coAuthors {
+			credits = append(credits, types.CommitCredit{
+				Key: key, CoAuthored: 1, LinesAdded: shareAdded, LinesDeleted: shareDeleted, Languages: shared,
+				BinaryFiles: binaryShare.BinaryFiles, BinaryBytesAdded: binaryShare.BinaryBytesAdded, BinaryBytesDeleted: binaryShare.BinaryBytesDeleted,
+			})
+		}
+		added -= shareAdded * len(coAuthors)
+		deleted -= shareDeleted * len(coAuthors)
+		languages = remainder
+		binary.BinaryFiles -= binaryShare.BinaryFiles * len(coAuthors)
+		binary.BinaryBytesAdded -= binaryShare.BinaryBytesAdded * len(coAuthors)
+		binary.BinaryBytesDeleted -= binaryShare.BinaryBytesDeleted * len(coAuthors)
+	case CoAuthorsColumn:
+		for _, key := range coAuthors {
+			credits = append(credits, types.CommitCredit{Key: key, CoAuthored: 1})
 		}
-
-		stats := repo.Contributors[contributorKey]
-		stats.LinesAdded += added
-		stats.LinesDeleted += deleted
-		stats.LinesChanged += added + deleted
 	}
 
+	author.LinesAdded, author.LinesDeleted, author.Languages = added, deleted, languageCredits(languages)
+	author.BinaryFiles, author.BinaryBytesAdded, author.BinaryBytesDeleted = binary.BinaryFiles, binary.BinaryBytesAdded, binary.BinaryBytesDeleted
+	author.Filtered = filteredCredits(filtered)
+	if a.concentration && !a.ownership {
+		for _, file := range files {
+			repo.AddFileWeight(file.Path, author.Key, file.Added+file.Deleted)
+		}
+	}
+	credits = append([]types.CommitCredit{author}, credits...)
+	var bucket time.Time
+	if a.interval != "" && !commit.AuthorTime.IsZero() {
+		bucket = types.BucketStart(commit.AuthorTime, a.interval)
+	}
+	for i := range credits {
+		credits[i].Time = commit.AuthorTime
+		credits[i].Bucket = bucket
+	}
+	repo.Credit(commit.Hash, credits...)
 	return nil
 }
+
+// languageCredits lists the lines changed per language in name order, leaving
+// out languages without changes
+func languageCredits(languages map[string]types.LanguageLines) []types.LanguageCredit {
+	credits := make([]types.LanguageCredit, 0, len(languages))
+	for language, lines := range languages {
+		if lines != (types.LanguageLines{}) {
+			credits = append(credits, types.LanguageCredit{Language: language, LanguageLines: lines})
+		}
+	}
+	sort.Slice(credits, func(i, j int) bool {
+		return credits[i].Language < credits[j].Language
+	})
+	return credits
+}
+
+// filteredCredits lists the lines each path filter left out in filter order
+func filteredCredits(filtered map[string]int) []types.FilteredCredit {
+	if len(filtered) == 0 {
+		return nil
+	}
+	credits := make([]types.FilteredCredit, 0, len(filtered))
+	for filter, lines := range filtered {
+		credits = append(credits, types.FilteredCredit{Filter: filter, Lines: lines})
+	}
+	sort.Slice(credits, func(i, j int) bool {
+		return credits[i].Filter < credits[j].Filter
+	})
+	return credits
+}
+
+// coAuthorsOf resolves the commit's Co-authored-by trailers to contributor
+// keys through both mailmaps and the usual grouping, skipping the author and
+// duplicates
+func (a *Analyzer) coAuthorsOf(repo *types.Repository, commit *commitRecord, authorKey string, local *Mailmap) []string {
+	if a.coAuthors == "" || len(commit.CoAuthors) == 0 {
+		return nil
+	}
+
+	seen := map[string]bool{authorKey: true}
+	coAuthors := make([]string, 0, len(commit.CoAuthors))
+	for _, identity := range commit.CoAuthors {
+		name, email := local.Resolve(identity.Name, identity.Email)
+		name, email = a.mailmap.Resolve(name, email)
+		key := a.registerContributor(repo, name, email)
+		if !seen[key] {
+			seen[key] = true
+			coAuthors = append(coAuthors, key)
+		}
+	}
+	return coAuthors
+}
@@ -1,10 +1,15 @@
 package analyzer
 
 import (
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"ganalyzer/pkg/types"
 )
 
 func TestAnalyzer_Integration(t *testing.T) {
@@ -37,6 +42,435 @@ func TestAnalyzer_Integration(t *testing.T) {
 	}
 }
 
+func TestAnalyzer_AuthorNameWithTab(t *testing.T) {
+	if !hasGit() {
+		t.Skip("Git not available, skipping integration test")
+	}
+
+	tempDir := createTestGitRepo(t)
+
+	if err := runCmd(tempDir, "git", "config", "user.name", "Tab\tUser"); err != nil {
+		t.Fatalf("git config user.name failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "test3.txt"), []byte("one\ntwo\nthree\n"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if err := runCmd(tempDir, "git", "add", "test3.txt"); err != nil {
+		t.Fatalf("git add failed: %v", err)
+	}
+	if err := runCmd(tempDir, "git", "commit", "-m", "Third commit"); err != nil {
+		t.Fatalf("git commit failed: %v", err)
+	}
+
+	repo, err := NewAnalyzer().AnalyzeRepository(tempDir)
+	if err != nil {
+		t.Fatalf("AnalyzeRepository failed: %v", err)
+	}
+
+	if len(repo.Contributors) != 2 {
+		t.Errorf("Expected 2 contributors, got %d", len(repo.Contributors))
+	}
+
+	tabUser := repo.Contributors["Tab\tUser"]
+	if tabUser == nil {
+		t.Fatal("Expected contributor with a tab in the name")
+	}
+	if tabUser.CommitCount != 1 || tabUser.LinesAdded != 3 {
+		t.Errorf("Unexpected stats for tab user: commits=%d, added=%d", tabUser.CommitCount, tabUser.LinesAdded)
+	}
+
+	testUser := repo.Contributors["Test User"]
+	if testUser == nil || testUser.CommitCount != 2 || testUser.LinesAdded != 4 {
+		t.Errorf("Unexpected stats for Test User: %+v", testUser)
+	}
+}
+
+func TestAnalyzer_EmailIdentity(t *testing.T) {
+	if !hasGit() {
+		t.Skip("Git not available, skipping integration test")
+	}
+
+	tempDir := createTestGitRepo(t)
+	commitAs(t, tempDir, "John Smith", "john.smith@acme.com", "a.txt", "a\n")
+	commitAs(t, tempDir, "John Smith", "jsmith@other.org", "b.txt", "b\n")
+	commitAs(t, tempDir, "Jane Doe", "jane@acme.com", "c.txt", "c\n")
+	commitAs(t, tempDir, "Jane Roe", "jane@acme.com", "d.txt", "d\n")
+
+	t.Run("group by name", func(t *testing.T) {
+		repo, err := NewAnalyzer().AnalyzeRepository(tempDir)
+		if err != nil {
+			t.Fatalf("AnalyzeRepository failed: %v", err)
+		}
+
+		john := repo.Contributors["John Smith"]
+		if john == nil || john.CommitCount != 2 {
+			t.Fatalf("Expected both John Smiths merged by name, got %+v", john)
+		}
+		if len(john.Emails) != 2 {
+			t.Errorf("Expected 2 emails recorded for John Smith, got %v", john.Emails)
+		}
+		// git log lists newest commits first
+		if john.Email != "jsmith@other.org" {
+			t.Errorf("Expected primary email jsmith@other.org, got %s", john.Email)
+		}
+
+		testUser := repo.Contributors["Test User"]
+		if testUser == nil || testUser.Email != "test@example.com" {
+			t.Errorf("Expected Test User email to be populated, got %+v", testUser)
+		}
+	})
+
+	t.Run("group by email", func(t *testing.T) {
+		repo, err := NewAnalyzerWithOptions(Options{GroupBy: GroupByEmail}).AnalyzeRepository(tempDir)
+		if err != nil {
+			t.Fatalf("AnalyzeRepository failed: %v", err)
+		}
+
+		if len(repo.Contributors) != 4 {
+			t.Errorf("Expected 4 contributors when grouping by email, got %d", len(repo.Contributors))
+		}
+
+		if john := repo.Contributors["john.smith@acme.com"]; john == nil || john.CommitCount != 1 {
+			t.Errorf("Expected a separate John Smith for john.smith@acme.com, got %+v", john)
+		}
+
+		jane := repo.Contributors["jane@acme.com"]
+		if jane == nil || jane.CommitCount != 2 {
+			t.Fatalf("Expected renamed Jane merged by email, got %+v", jane)
+		}
+		if jane.Name != "Jane Roe" || len(jane.Aliases) != 1 || jane.Aliases[0] != "Jane Doe" {
+			t.Errorf("Expected Jane Roe with alias Jane Doe, got %s %v", jane.Name, jane.Aliases)
+		}
+	})
+}
+
+func TestAnalyzer_Mailmap(t *testing.T) {
+	if !hasGit() {
+		t.Skip("Git not available, skipping integration test")
+	}
+
+	tempDir := createTestGitRepo(t)
+	commitAs(t, tempDir, "tuser", "test@laptop.local", "a.txt", "a\n")
+
+	mailmap, err := ParseMailmap(strings.NewReader("Test User <test@example.com> <test@laptop.local>\n"))
+	if err != nil {
+		t.Fatalf("ParseMailmap failed: %v", err)
+	}
+
+	repo, err := NewAnalyzerWithOptions(Options{Mailmap: mailmap}).AnalyzeRepository(tempDir)
+	if err != nil {
+		t.Fatalf("AnalyzeRepository failed: %v", err)
+	}
+
+	if len(repo.Contributors) != 1 {
+		t.Errorf("Expected mailmap to merge identities into 1 contributor, got %d", len(repo.Contributors))
+	}
+	testUser := repo.Contributors["Test User"]
+	if testUser == nil || testUser.CommitCount != 3 {
+		t.Fatalf("Expected 3 commits for Test User, got %+v", testUser)
+	}
+	if len(testUser.Emails) != 1 {
+		t.Errorf("Expected mapped email only, got %v", testUser.Emails)
+	}
+
+	hits := mailmap.Hits()
+	if len(hits) != 1 || hits[0].Count != 1 {
+		t.Errorf("Expected a single mailmap hit, got %+v", hits)
+	}
+}
+
+func TestAnalyzer_IdentityRules(t *testing.T) {
+	if !hasGit() {
+		t.Skip("Git not available, skipping integration test")
+	}
+
+	tempDir := createTestGitRepo(t)
+	commitAs(t, tempDir, "Jan Novák", "jan.novak@acme.com", "a.txt", "a\n")
+	commitAs(t, tempDir, "Jan Novak", "jan.novak@interns.acme.com", "b.txt", "b\n")
+	commitAs(t, tempDir, "Honza", "honza@home.net", "c.txt", "c\n")
+
+	rules, err := ParseIdentityRules(strings.NewReader(`{
+		"merge": [{"name": "Test User Sr", "match": ["Honza", "test@example.com"]}],
+		"never_merge": [["jan.novak@acme.com", "jan.novak@interns.acme.com"]]
+	}`))
+	if err != nil {
+		t.Fatalf("ParseIdentityRules failed: %v", err)
+	}
+
+	a := NewAnalyzerWithOptions(Options{Normalize: true, Rules: rules})
+	repo, err := a.AnalyzeRepository(tempDir)
+	if err != nil {
+		t.Fatalf("AnalyzeRepository failed: %v", err)
+	}
+	gs := types.NewGlobalStats()
+	gs.AddRepository(repo)
+	a.ResolveIdentities(gs)
+
+	if len(gs.Contributors) != 3 {
+		t.Fatalf("Expected 3 contributors, got %d", len(gs.Contributors))
+	}
+
+	var merged *types.ContributorStats
+	novaks := 0
+	for _, contributor := range gs.Contributors {
+		switch contributor.Name {
+		case "Test User Sr":
+			merged = contributor
+		case "Jan Novák", "Jan Novak":
+			novaks++
+		}
+	}
+	if novaks != 2 {
+		t.Errorf("Expected never-merge rule to keep both Jan Novák apart, got %d", novaks)
+	}
+	if merged == nil || merged.CommitCount != 3 {
+		t.Fatalf("Expected merge rule to combine 3 commits under the display name, got %+v", merged)
+	}
+	if len(merged.Aliases) != 2 {
+		t.Errorf("Expected original names as aliases, got %v", merged.Aliases)
+	}
+}
+
+func TestAnalyzer_CoAuthors(t *testing.T) {
+	if !hasGit() {
+		t.Skip("Git not available, skipping integration test")
+	}
+
+	tempDir := createTestGitRepo(t)
+	commitAs(t, tempDir, "Test User", "test@example.com", ".mailmap", "Bob Builder <bob@example.com> <bob@old.com>\n")
+	if err := os.WriteFile(filepath.Join(tempDir, "pair.txt"), []byte("1\n2\n3\n4\n"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if err := runCmd(tempDir, "git", "add", "pair.txt"); err != nil {
+		t.Fatalf("git add failed: %v", err)
+	}
+	trailers := "Co-authored-by: Bobby <bob@old.com>\nCo-Authored-By: Alice <alice@example.com>"
+	if err := runCmd(tempDir, "git", "commit", "--author", "Alice <alice@example.com>", "-m", "Pair on feature", "-m", trailers); err != nil {
+		t.Fatalf("git commit failed: %v", err)
+	}
+
+	tests := []struct {
+		policy      string
+		bobCommits  int
+		bobLines    int
+		bobCoAuthor int
+		aliceLines  int
+	}{
+		{CoAuthorsFull, 1, 4, 1, 4},
+		{CoAuthorsSplit, 0, 2, 1, 2},
+		{CoAuthorsColumn, 0, 0, 1, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			repo, err := NewAnalyzerWithOptions(Options{CoAuthors: tt.policy}).AnalyzeRepository(tempDir)
+			if err != nil {
+				t.Fatalf("AnalyzeRepository failed: %v", err)
+			}
+
+			bob := repo.Contributors["Bob Builder"]
+			if bob == nil {
+				t.Fatalf("Expected co-author resolved through .mailmap, got %v", repo.Contributors)
+			}
+			if bob.CommitCount != tt.bobCommits || bob.LinesAdded != tt.bobLines || bob.CoAuthoredCommits != tt.bobCoAuthor {
+				t.Errorf("Unexpected co-author stats: %+v", bob)
+			}
+			// Co-authoring counts as activity whether or not the commit is credited
+			if bob.ActiveDays != 1 || bob.LastCommit.IsZero() {
+				t.Errorf("Expected the co-authored commit to count towards tenure, got %+v", bob)
+			}
+
+			alice := repo.Contributors["Alice"]
+			if alice == nil || alice.CommitCount != 1 || alice.LinesAdded != tt.aliceLines || alice.CoAuthoredCommits != 0 {
+				t.Errorf("Unexpected author stats: %+v", alice)
+			}
+		})
+	}
+
+	repo, err := NewAnalyzer().AnalyzeRepository(tempDir)
+	if err != nil {
+		t.Fatalf("AnalyzeRepository failed: %v", err)
+	}
+	if _, ok := repo.Contributors["Bob Builder"]; ok {
+		t.Error("Expected trailers to be ignored without a co-author policy")
+	}
+}
+
+func TestAnalyzer_SharedHistory(t *testing.T) {
+	if !hasGit() {
+		t.Skip("Git not available, skipping integration test")
+	}
+
+	upstream := createTestGitRepo(t)
+	fork := filepath.Join(t.TempDir(), "fork")
+	if err := runCmd(upstream, "git", "clone", "-q", upstream, fork); err != nil {
+		t.Fatalf("git clone failed: %v", err)
+	}
+	if err := runCmd(fork, "git", "config", "user.email", "test@example.com"); err != nil {
+		t.Fatalf("git config failed: %v", err)
+	}
+	if err := runCmd(fork, "git", "config", "user.name", "Test User"); err != nil {
+		t.Fatalf("git config failed: %v", err)
+	}
+	commitAs(t, fork, "Test User", "test@example.com", "fork.txt", "fork\n")
+
+	a := NewAnalyzer()
+	gs := types.NewGlobalStats()
+	for _, path := range []string{upstream, fork} {
+		repo, err := a.AnalyzeRepository(path)
+		if err != nil {
+			t.Fatalf("AnalyzeRepository failed: %v", err)
+		}
+		if len(repo.RootCommits) != 1 {
+			t.Errorf("Expected a single root commit, got %v", repo.RootCommits)
+		}
+		gs.AddRepository(repo)
+	}
+
+	if got := gs.Contributors["Test User"].CommitCount; got != 3 {
+		t.Errorf("Expected 3 unique commits for Test User, got %d", got)
+	}
+	if got := gs.Repositories[1].Contributors["Test User"].CommitCount; got != 3 {
+		t.Errorf("Expected the fork to report all 3 of its commits, got %d", got)
+	}
+	if gs.DuplicateCommits != 2 || len(gs.SharedHistory()) != 1 {
+		t.Errorf("Expected 2 duplicates in one shared group, got %d and %v", gs.DuplicateCommits, gs.SharedHistory())
+	}
+}
+
+func TestAnalyzer_TimeWindow(t *testing.T) {
+	if !hasGit() {
+		t.Skip("Git not available, skipping integration test")
+	}
+
+	tempDir := createTestGitRepo(t)
+
+	past := NewAnalyzerWithOptions(Options{Until: time.Now().AddDate(-1, 0, 0)})
+	repo, err := past.AnalyzeRepository(tempDir)
+	if err != nil {
+		t.Fatalf("AnalyzeRepository failed: %v", err)
+	}
+	if len(repo.Contributors) != 0 {
+		t.Errorf("Expected no contributors before the repository existed, got %d", len(repo.Contributors))
+	}
+
+	recent := NewAnalyzerWithOptions(Options{Since: time.Now().AddDate(0, 0, -1)})
+	repo, err = recent.AnalyzeRepository(tempDir)
+	if err != nil {
+		t.Fatalf("AnalyzeRepository failed: %v", err)
+	}
+	testUser := repo.Contributors["Test User"]
+	if testUser == nil {
+		t.Fatal("Expected 'Test User' contributor within the window")
+	}
+	if testUser.CommitCount != 2 {
+		t.Errorf("Expected 2 commits within the window, got %d", testUser.CommitCount)
+	}
+	if testUser.LinesAdded != 4 {
+		t.Errorf("Expected 4 lines added within the window, got %d", testUser.LinesAdded)
+	}
+
+	// The window applies to author dates, not to the (current) committer date
+	commitAt(t, tempDir, "old.txt", "one\ntwo\n", "2020-01-15T12:00:00Z")
+	early := NewAnalyzerWithOptions(Options{Until: time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)})
+	repo, err = early.AnalyzeRepository(tempDir)
+	if err != nil {
+		t.Fatalf("AnalyzeRepository failed: %v", err)
+	}
+	if testUser := repo.Contributors["Test User"]; testUser == nil || testUser.CommitCount != 1 || testUser.LinesAdded != 2 {
+		t.Errorf("Expected only the commit authored before the end of the window, got %+v", testUser)
+	}
+
+	repo, err = recent.AnalyzeRepository(tempDir)
+	if err != nil {
+		t.Fatalf("AnalyzeRepository failed: %v", err)
+	}
+	if testUser := repo.Contributors["Test User"]; testUser.CommitCount != 2 {
+		t.Errorf("Expected the commit authored before the window to be left out, got %d commits", testUser.CommitCount)
+	}
+
+	// A commit with an old committer date must not hide the ones behind it
+	if err := os.WriteFile(filepath.Join(tempDir, "skewed.txt"), []byte("skewed\n"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if err := runCmd(tempDir, "git", "add", "skewed.txt"); err != nil {
+		t.Fatalf("git add failed: %v", err)
+	}
+	skewed := exec.Command("git", "commit", "-q", "-m", "Add skewed.txt")
+	skewed.Dir = tempDir
+	skewed.Env = append(os.Environ(), "GIT_COMMITTER_DATE=2000-01-01T00:00:00Z")
+	if err := skewed.Run(); err != nil {
+		t.Fatalf("git commit failed: %v", err)
+	}
+	commitAs(t, tempDir, "Test User", "test@example.com", "later.txt", "later\n")
+
+	repo, err = recent.AnalyzeRepository(tempDir)
+	if err != nil {
+		t.Fatalf("AnalyzeRepository failed: %v", err)
+	}
+	if testUser := repo.Contributors["Test User"]; testUser.CommitCount != 4 {
+		t.Errorf("Expected every commit authored within the window, got %d commits", testUser.CommitCount)
+	}
+}
+
+func TestAnalyzer_Interval(t *testing.T) {
+	if !hasGit() {
+		t.Skip("Git not available, skipping integration test")
+	}
+
+	tempDir := createTestGitRepo(t)
+	commitAt(t, tempDir, "old.txt", "one\ntwo\nthree\n", "2024-02-29T23:30:00Z")
+
+	analyzer := NewAnalyzerWithOptions(Options{Interval: types.IntervalQuarter})
+	repo, err := analyzer.AnalyzeRepository(tempDir)
+	if err != nil {
+		t.Fatalf("AnalyzeRepository failed: %v", err)
+	}
+
+	series := repo.Contributors["Test User"].Series()
+	if len(series) != 2 {
+		t.Fatalf("Expected 2 quarters of activity, got %+v", series)
+	}
+	first := series[0]
+	if !first.Start.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) || first.Commits != 1 || first.LinesAdded != 3 {
+		t.Errorf("Unexpected backdated bucket: %+v", first)
+	}
+	if series[1].Commits != 2 || series[1].LinesAdded != 4 {
+		t.Errorf("Unexpected recent bucket: %+v", series[1])
+	}
+}
+
+func TestAnalyzer_CommitDates(t *testing.T) {
+	if !hasGit() {
+		t.Skip("Git not available, skipping integration test")
+	}
+
+	tempDir := createTestGitRepo(t)
+	commitAt(t, tempDir, "a.txt", "a\n", "2024-01-10T08:00:00Z")
+	commitAt(t, tempDir, "b.txt", "b\n", "2024-01-10T17:00:00Z")
+	commitAt(t, tempDir, "c.txt", "c\n", "2024-01-20T12:00:00Z")
+
+	repo, err := NewAnalyzer().AnalyzeRepository(tempDir)
+	if err != nil {
+		t.Fatalf("AnalyzeRepository failed: %v", err)
+	}
+
+	stats := repo.Contributors["Test User"]
+	if !stats.FirstCommit.Equal(time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the first commit on 2024-01-10 08:00, got %v", stats.FirstCommit)
+	}
+	if time.Since(stats.LastCommit) > time.Hour {
+		t.Errorf("Expected the last commit to be recent, got %v", stats.LastCommit)
+	}
+	// Two backdated days plus today
+	if stats.ActiveDays != 3 {
+		t.Errorf("Expected 3 active days, got %d", stats.ActiveDays)
+	}
+	if stats.LongestGapDays < 9 {
+		t.Errorf("Expected a gap of at least the 9 days in January, got %d", stats.LongestGapDays)
+	}
+}
+
 func TestAnalyzer_NonexistentRepository(t *testing.T) {
 	analyzer := NewAnalyzer()
 	_, err := analyzer.AnalyzeRepository("/nonexistent/repo")
@@ -104,6 +538,36 @@ func createTestGitRepo(t *testing.T) string {
 	return tempDir
 }
 
+func commitAs(t *testing.T, dir, name, email, file, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if err := runCmd(dir, "git", "add", file); err != nil {
+		t.Fatalf("git add failed: %v", err)
+	}
+	author := fmt.Sprintf("%s <%s>", name, email)
+	if err := runCmd(dir, "git", "commit", "--author", author, "-m", "Add "+file); err != nil {
+		t.Fatalf("git commit failed: %v", err)
+	}
+}
+
+// commitAt commits a file as the configured user with the given author date
+func commitAt(t *testing.T, dir, file, content, date string) {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if err := runCmd(dir, "git", "add", file); err != nil {
+		t.Fatalf("git add failed: %v", err)
+	}
+	if err := runCmd(dir, "git", "commit", "--date", date, "-m", "Add "+file); err != nil {
+		t.Fatalf("git commit failed: %v", err)
+	}
+}
+
 func runCmd(dir, name string, args ...string) error {
 	cmd := exec.Command(name, args...)
 	cmd.Dir = dir
@@ -0,0 +1,96 @@
+package analyzer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// blobSizer looks up blob sizes through one long-running
+// `git cat-file --batch-check` process per repository
+type blobSizer struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+}
+
+func newBlobSizer(repoPath string) (*blobSizer, error) {
+	cmd := exec.Command("git", "-C", repoPath, "cat-file", "--batch-check")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("git cat-file failed: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("git cat-file failed: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("git cat-file failed: %w", err)
+	}
+	return &blobSizer{cmd: cmd, stdin: stdin, stdout: bufio.NewReader(stdout)}, nil
+}
+
+// size returns the size of the blob at path in commit, or 0 when there is
+// none, as before a file is added or after it is deleted
+func (s *blobSizer) size(commit, path string) (int, error) {
+	// The batch protocol is line based; such paths cannot be asked for
+	if strings.ContainsAny(path, "\n") {
+		return 0, nil
+	}
+	object := commit + ":" + path
+	if _, err := fmt.Fprintln(s.stdin, object); err != nil {
+		return 0, fmt.Errorf("git cat-file failed: %w", err)
+	}
+	line, err := s.stdout.ReadString('\n')
+	if err != nil {
+		return 0, fmt.Errorf("git cat-file failed: %w", err)
+	}
+
+	// "<oid> blob <size>", or "<object> missing" and the like
+	line = strings.TrimSuffix(line, "\n")
+	if strings.HasPrefix(line, object+" ") {
+		return 0, nil
+	}
+	fields := strings.Fields(line)
+	if len(fields) != 3 || fields[1] != "blob" {
+		return 0, nil
+	}
+	size, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return 0, fmt.Errorf("unexpected git cat-file output %q", line)
+	}
+	return size, nil
+}
+
+// change returns by how many bytes the commit grew and shrank a binary file,
+// compared with its first parent
+func (s *blobSizer) change(commit string, file fileStat) (grown, shrunk int, err error) {
+	oldPath := file.Path
+	if file.OldPath != "" {
+		oldPath = file.OldPath
+	}
+	before, err := s.size(commit+"^", oldPath)
+	if err != nil {
+		return 0, 0, err
+	}
+	after, err := s.size(commit, file.Path)
+	if err != nil {
+		return 0, 0, err
+	}
+	if after > before {
+		return after - before, 0, nil
+	}
+	return 0, before - after, nil
+}
+
+// Close stops the git process
+func (s *blobSizer) Close() error {
+	if s == nil {
+		return nil
+	}
+	s.stdin.Close()
+	return s.cmd.Wait()
+}
@@ -0,0 +1,72 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzer_BinaryFiles(t *testing.T) {
+	if !hasGit() {
+		t.Skip("Git not available, skipping integration test")
+	}
+
+	asset := func(size int) string {
+		return "\x89PNG\x00" + strings.Repeat("\x00\x01", (size-5)/2)
+	}
+
+	tempDir := createTestGitRepo(t)
+	commitAs(t, tempDir, "Dana", "dana@example.com", "logo.png", asset(2005))
+	commitAs(t, tempDir, "Dana", "dana@example.com", "logo.png", asset(1005))
+	if err := runCmd(tempDir, "git", "mv", "logo.png", "brand.png"); err != nil {
+		t.Fatalf("git mv failed: %v", err)
+	}
+	commitAs(t, tempDir, "Dana", "dana@example.com", "brand.png", asset(1105))
+	if err := os.Mkdir(filepath.Join(tempDir, "vendor"), 0755); err != nil {
+		t.Fatalf("Mkdir failed: %v", err)
+	}
+	commitAs(t, tempDir, "Dana", "dana@example.com", "vendor/icon.png", asset(505))
+	if err := os.Remove(filepath.Join(tempDir, "brand.png")); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if err := runCmd(tempDir, "git", "commit", "-qam", "Remove brand.png", "--author", "Dana <dana@example.com>"); err != nil {
+		t.Fatalf("git commit failed: %v", err)
+	}
+
+	filter, err := NewPathFilter(nil, nil, true, false)
+	if err != nil {
+		t.Fatalf("NewPathFilter failed: %v", err)
+	}
+
+	tests := []struct {
+		bytes   bool
+		added   int
+		deleted int
+	}{
+		{false, 0, 0},
+		// Added, shrunk, renamed and grown, then deleted; vendor/ is filtered
+		{true, 2005 + 100, 1000 + 1105},
+	}
+	for _, tt := range tests {
+		repo, err := NewAnalyzerWithOptions(Options{Paths: filter, BinaryBytes: tt.bytes}).AnalyzeRepository(tempDir)
+		if err != nil {
+			t.Fatalf("AnalyzeRepository failed: %v", err)
+		}
+
+		dana := repo.Contributors["Dana"]
+		if dana == nil {
+			t.Fatalf("Missing contributor Dana: %v", repo.Contributors)
+		}
+		if dana.CommitCount != 5 || dana.BinaryFiles != 4 || dana.LinesChanged != 0 {
+			t.Errorf("bytes=%v: expected 5 commits changing 4 binary files and no lines, got %+v", tt.bytes, dana)
+		}
+		if dana.BinaryBytesAdded != tt.added || dana.BinaryBytesDeleted != tt.deleted {
+			t.Errorf("bytes=%v: expected %d bytes added and %d deleted, got %d and %d",
+				tt.bytes, tt.added, tt.deleted, dana.BinaryBytesAdded, dana.BinaryBytesDeleted)
+		}
+		if repo.Contributors["Test User"].BinaryFiles != 0 {
+			t.Errorf("bytes=%v: expected no binary files for text commits", tt.bytes)
+		}
+	}
+}
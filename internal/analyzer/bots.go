@@ -0,0 +1,155 @@
+package analyzer
+
+import (
+	"regexp"
+	"strings"
+
+	"ganalyzer/pkg/types"
+)
+
+const (
+	// Ways of handling detected bots, selected with -bots
+	BotsExclude  = "exclude"
+	BotsFlag     = "flag"
+	BotsSeparate = "separate"
+
+	// A contributor with at least minAutomatedCommits commits, of which at
+	// least automatedShare look machine-generated, is treated as a bot
+	minAutomatedCommits = 5
+	automatedShare      = 0.9
+)
+
+// defaultBotPatterns are case-insensitive substrings of the names and emails
+// used by common automation accounts
+var defaultBotPatterns = []string{
+	"[bot]",
+	"dependabot",
+	"renovate",
+	"greenkeeper",
+	"github-actions",
+	"github actions",
+	"semantic-release",
+	"pre-commit-ci",
+	"snyk-bot",
+	"imgbot",
+}
+
+// botLocalParts are email local parts that belong to automation rather than people
+var botLocalParts = map[string]bool{
+	"noreply":    true,
+	"no-reply":   true,
+	"bot":        true,
+	"buildbot":   true,
+	"jenkins":    true,
+	"automation": true,
+	"actions":    true,
+	"action":     true,
+}
+
+// sharedLocalParts are email local parts of shared mailboxes that people
+// commit from as well as tools; only their no-reply forms count as automation,
+// otherwise the commit subjects decide
+var sharedLocalParts = map[string]bool{
+	"ci":      true,
+	"build":   true,
+	"release": true,
+}
+
+// automatedSubjects match lowercased commit subjects typically written by tools
+var automatedSubjects = []*regexp.Regexp{
+	regexp.MustCompile(`^bump \S+ from \S+ to \S+`),
+	regexp.MustCompile(`^(build|chore|fix)\(deps(-dev)?\): `),
+	regexp.MustCompile(`^(chore\(deps\): )?update (dependency|module|plugin|docker tag) `),
+	regexp.MustCompile(`^chore\(release\): `),
+	regexp.MustCompile(`^(automated|auto-generated|autogenerated) `),
+	regexp.MustCompile(`^\[create-pull-request\] `),
+}
+
+// BotDetector recognizes automation accounts by their name or email, or by a
+// history made almost entirely of machine-generated commit subjects
+type BotDetector struct {
+	patterns []string
+}
+
+// NewBotDetector creates a detector using the default patterns plus extra
+// case-insensitive substrings of names or emails
+func NewBotDetector(extra []string) *BotDetector {
+	patterns := append([]string(nil), defaultBotPatterns...)
+	for _, pattern := range extra {
+		if pattern = strings.ToLower(strings.TrimSpace(pattern)); pattern != "" {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return &BotDetector{patterns: patterns}
+}
+
+// automatedSubject reports whether a commit subject looks machine-generated
+func (d *BotDetector) automatedSubject(subject string) bool {
+	if d == nil {
+		return false
+	}
+	subject = strings.ToLower(strings.TrimSpace(subject))
+	for _, pattern := range automatedSubjects {
+		if pattern.MatchString(subject) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesIdentity reports whether a name or email belongs to an automation account
+func (d *BotDetector) matchesIdentity(name, email string) bool {
+	name, email = strings.ToLower(name), strings.ToLower(email)
+	for _, pattern := range d.patterns {
+		if strings.Contains(name, pattern) || strings.Contains(email, pattern) {
+			return true
+		}
+	}
+
+	for _, word := range strings.Fields(name) {
+		if word == "bot" {
+			return true
+		}
+	}
+
+	// Personal GitHub addresses (id+user@users.noreply.github.com) are not bots
+	local, domain, _ := strings.Cut(email, "@")
+	if botLocalParts[local] {
+		return true
+	}
+	return sharedLocalParts[local] && (strings.Contains(domain, "noreply") || strings.Contains(domain, "no-reply"))
+}
+
+// isBot reports whether any identity of the contributor matches, or the
+// contributor's commits are predominantly automated
+func (d *BotDetector) isBot(stats *types.ContributorStats) bool {
+	for _, name := range contributorNames(stats) {
+		if d.matchesIdentity(name, "") {
+			return true
+		}
+	}
+	for _, email := range contributorEmails(stats) {
+		if d.matchesIdentity("", email) {
+			return true
+		}
+	}
+
+	return stats.CommitCount >= minAutomatedCommits &&
+		float64(stats.AutomatedCommits) >= automatedShare*float64(stats.CommitCount)
+}
+
+// markBots flags bot contributors globally and in every repository. It runs
+// after identity resolution so evidence from all merged identities counts.
+func (a *Analyzer) markBots(gs *types.GlobalStats) {
+	for key, stats := range gs.Contributors {
+		if !a.bots.isBot(stats) {
+			continue
+		}
+		stats.IsBot = true
+		for _, repo := range gs.Repositories {
+			if repoStats, ok := repo.Contributors[key]; ok {
+				repoStats.IsBot = true
+			}
+		}
+	}
+}
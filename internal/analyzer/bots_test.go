@@ -0,0 +1,92 @@
+package analyzer
+
+import (
+	"testing"
+
+	"ganalyzer/pkg/types"
+)
+
+func TestBotDetector_MatchesIdentity(t *testing.T) {
+	detector := NewBotDetector([]string{" Release-Manager ", ""})
+
+	tests := []struct {
+		name     string
+		email    string
+		expected bool
+	}{
+		{"dependabot[bot]", "49699333+dependabot[bot]@users.noreply.github.com", true},
+		{"Renovate Bot", "bot@renovateapp.com", true},
+		{"github-actions", "41898282+github-actions@users.noreply.github.com", true},
+		{"Deploy Bot", "deploy@acme.com", true},
+		{"Jenkins", "jenkins@ci.acme.com", true},
+		{"Acme", "noreply@acme.com", true},
+		{"release-manager", "rm@acme.com", true},
+		{"Jane Doe", "12345+jane@users.noreply.github.com", false},
+		{"Release Team", "release@acme.com", false},
+		{"Sam Builder", "build@acme.com", false},
+		{"CI", "ci@noreply.acme.com", true},
+		{"Acme Release", "release@no-reply.acme.com", true},
+		{"Abbott Smith", "abbott@acme.com", false},
+	}
+
+	for _, tt := range tests {
+		if got := detector.matchesIdentity(tt.name, tt.email); got != tt.expected {
+			t.Errorf("matchesIdentity(%q, %q) = %v, expected %v", tt.name, tt.email, got, tt.expected)
+		}
+	}
+}
+
+func TestBotDetector_AutomatedSubject(t *testing.T) {
+	detector := NewBotDetector(nil)
+
+	tests := []struct {
+		subject  string
+		expected bool
+	}{
+		{"Bump lodash from 4.17.20 to 4.17.21", true},
+		{"build(deps-dev): bump eslint from 8.0.0 to 8.1.0", true},
+		{"chore(deps): update dependency react to v18", true},
+		{"Update dependency golang to v1.22", true},
+		{"chore(release): 1.4.0", true},
+		{"Fix crash when bumping version", false},
+		{"Update README", false},
+	}
+
+	for _, tt := range tests {
+		if got := detector.automatedSubject(tt.subject); got != tt.expected {
+			t.Errorf("automatedSubject(%q) = %v, expected %v", tt.subject, got, tt.expected)
+		}
+	}
+
+	var none *BotDetector
+	if none.automatedSubject("Bump a from 1 to 2") {
+		t.Error("Expected nil detector to report no automated subjects")
+	}
+}
+
+func TestAnalyzer_MarkBots(t *testing.T) {
+	repo := types.NewRepository("/path/to/repo")
+	repo.Contributors["Release Script"] = &types.ContributorStats{Name: "Release Script", Email: "ops@acme.com", CommitCount: 10, AutomatedCommits: 10}
+	repo.Contributors["Jane Doe"] = &types.ContributorStats{Name: "Jane Doe", Email: "jane@acme.com", CommitCount: 10, AutomatedCommits: 3}
+	repo.Contributors["Sam"] = &types.ContributorStats{Name: "Sam", Email: "sam@acme.com", CommitCount: 2, AutomatedCommits: 2}
+	repo.Contributors["dependabot[bot]"] = &types.ContributorStats{Name: "dependabot[bot]", CommitCount: 1}
+
+	gs := types.NewGlobalStats()
+	gs.AddRepository(repo)
+	NewAnalyzerWithOptions(Options{Bots: NewBotDetector(nil)}).ResolveIdentities(gs)
+
+	expected := map[string]bool{
+		"Release Script":  true,
+		"Jane Doe":        false,
+		"Sam":             false,
+		"dependabot[bot]": true,
+	}
+	for key, bot := range expected {
+		if gs.Contributors[key].IsBot != bot {
+			t.Errorf("Expected IsBot=%v for %s", bot, key)
+		}
+		if repo.Contributors[key].IsBot != bot {
+			t.Errorf("Expected repository entry of %s to have IsBot=%v", key, bot)
+		}
+	}
+}
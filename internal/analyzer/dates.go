@@ -0,0 +1,98 @@
+package analyzer
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Number of days in a week for "<n> weeks ago" expressions
+	daysPerWeek = 7
+)
+
+// Absolute date layouts accepted by ParseSince and ParseUntil, most specific first
+var dateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+var relativeDateRegex = regexp.MustCompile(`^(\d+)\s*(second|minute|hour|day|week|month|year)s?\s+ago$`)
+
+// ParseSince resolves a lower time bound such as "2024-01-01" or "90 days ago".
+// Date-only values start at midnight local time.
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	t, _, err := parseDate(value, now)
+	return t, err
+}
+
+// ParseUntil resolves an upper time bound such as "2024-03-31" or "yesterday".
+// Date-only values are inclusive and extend to the end of that day.
+func ParseUntil(value string, now time.Time) (time.Time, error) {
+	t, dateOnly, err := parseDate(value, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if dateOnly {
+		t = t.AddDate(0, 0, 1).Add(-time.Second)
+	}
+	return t, nil
+}
+
+// parseDate understands the absolute layouts above plus a subset of git's
+// approxidate syntax: "now", "today", "yesterday" and "<n> <unit>s ago".
+// The second return value reports whether only a calendar day was given.
+func parseDate(value string, now time.Time) (time.Time, bool, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, false, fmt.Errorf("empty date")
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch strings.ToLower(value) {
+	case "now":
+		return now, false, nil
+	case "today":
+		return today, true, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), true, nil
+	}
+
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+			return t, layout == "2006-01-02", nil
+		}
+	}
+
+	matches := relativeDateRegex.FindStringSubmatch(strings.ToLower(value))
+	if matches == nil {
+		return time.Time{}, false, fmt.Errorf("unrecognized date %q", value)
+	}
+
+	n, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid amount in %q: %w", value, err)
+	}
+
+	switch matches[2] {
+	case "second":
+		return now.Add(-time.Duration(n) * time.Second), false, nil
+	case "minute":
+		return now.Add(-time.Duration(n) * time.Minute), false, nil
+	case "hour":
+		return now.Add(-time.Duration(n) * time.Hour), false, nil
+	case "day":
+		return now.AddDate(0, 0, -n), false, nil
+	case "week":
+		return now.AddDate(0, 0, -n*daysPerWeek), false, nil
+	case "month":
+		return now.AddDate(0, -n, 0), false, nil
+	default:
+		return now.AddDate(-n, 0, 0), false, nil
+	}
+}
@@ -0,0 +1,113 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, time.June, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		input    string
+		expected time.Time
+		wantErr  bool
+	}{
+		{
+			name:     "date only",
+			input:    "2024-01-01",
+			expected: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "rfc3339",
+			input:    "2024-01-01T08:00:00Z",
+			expected: time.Date(2024, time.January, 1, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "date and time",
+			input:    "2024-01-01 08:15:00",
+			expected: time.Date(2024, time.January, 1, 8, 15, 0, 0, time.UTC),
+		},
+		{
+			name:     "days ago",
+			input:    "90 days ago",
+			expected: now.AddDate(0, 0, -90),
+		},
+		{
+			name:     "singular unit",
+			input:    "1 week ago",
+			expected: now.AddDate(0, 0, -7),
+		},
+		{
+			name:     "months ago mixed case",
+			input:    "3 Months Ago",
+			expected: now.AddDate(0, -3, 0),
+		},
+		{
+			name:     "hours ago",
+			input:    "12 hours ago",
+			expected: now.Add(-12 * time.Hour),
+		},
+		{
+			name:     "yesterday",
+			input:    "yesterday",
+			expected: time.Date(2024, time.June, 14, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "now",
+			input:    "now",
+			expected: now,
+		},
+		{
+			name:    "empty",
+			input:   "  ",
+			wantErr: true,
+		},
+		{
+			name:    "garbage",
+			input:   "sometime soon",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseSince(tt.input, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseSince(%q) expected error, got %v", tt.input, result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSince(%q) failed: %v", tt.input, err)
+			}
+			if !result.Equal(tt.expected) {
+				t.Errorf("ParseSince(%q) = %v, want %v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseUntil_DateOnlyIsInclusive(t *testing.T) {
+	now := time.Date(2024, time.June, 15, 10, 30, 0, 0, time.UTC)
+
+	result, err := ParseUntil("2024-03-31", now)
+	if err != nil {
+		t.Fatalf("ParseUntil failed: %v", err)
+	}
+
+	expected := time.Date(2024, time.March, 31, 23, 59, 59, 0, time.UTC)
+	if !result.Equal(expected) {
+		t.Errorf("ParseUntil(\"2024-03-31\") = %v, want %v", result, expected)
+	}
+
+	result, err = ParseUntil("2 days ago", now)
+	if err != nil {
+		t.Fatalf("ParseUntil failed: %v", err)
+	}
+	if !result.Equal(now.AddDate(0, 0, -2)) {
+		t.Errorf("ParseUntil(\"2 days ago\") = %v, want %v", result, now.AddDate(0, 0, -2))
+	}
+}
@@ -0,0 +1,141 @@
+package analyzer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"ganalyzer/pkg/types"
+)
+
+const (
+	// DedupePatchID collapses commits with the same `git patch-id --stable`
+	DedupePatchID = "patch-id"
+	// DedupeCherryPick collapses commits whose "(cherry picked from commit ...)"
+	// line names a commit that is also analyzed
+	DedupeCherryPick = "cherry-pick"
+)
+
+// cherryPickedFrom matches the line `git cherry-pick -x` appends to messages
+var cherryPickedFrom = regexp.MustCompile(`\(cherry picked from commit ([0-9a-f]{7,64})\)`)
+
+// collapseDuplicates finds commits that repeat another analyzed commit, such
+// as a fix cherry-picked onto several release branches, and collapses all
+// but one of them
+func (a *Analyzer) collapseDuplicates(repo *types.Repository, revisions []string) error {
+	var duplicates []string
+	var err error
+
+	switch a.dedupe {
+	case DedupePatchID:
+		duplicates, err = patchIDDuplicates(repo, revisions)
+	case DedupeCherryPick:
+		duplicates, err = cherryPickDuplicates(repo, revisions)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, hash := range duplicates {
+		repo.Collapse(hash)
+	}
+	return nil
+}
+
+// patchIDDuplicates returns every credited commit whose patch-id was already
+// seen on an older credited commit. git log lists newest first, so the oldest
+// commit of each group, normally the original, is the one kept. Commits git
+// lists but the time window left out are ignored.
+func patchIDDuplicates(repo *types.Repository, revisions []string) ([]string, error) {
+	repoPath := repo.Path
+	logArgs := append([]string{"-C", repoPath, "log", "-p", "--no-color", "--no-ext-diff", "--format=commit %H"}, revisions...)
+	logCmd := exec.Command("git", logArgs...)
+	patchCmd := exec.Command("git", "-C", repoPath, "patch-id", "--stable")
+
+	var logStderr, patchStderr, output bytes.Buffer
+	logCmd.Stderr = &logStderr
+	patchCmd.Stderr = &patchStderr
+	patchCmd.Stdout = &output
+
+	pipe, err := logCmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("git log failed: %w", err)
+	}
+	patchCmd.Stdin = pipe
+
+	if err := logCmd.Start(); err != nil {
+		return nil, fmt.Errorf("git log failed: %w", err)
+	}
+	patchErr := patchCmd.Run()
+	if err := logCmd.Wait(); err != nil {
+		return nil, fmt.Errorf("git log failed: %w: %s", err, strings.TrimSpace(logStderr.String()))
+	}
+	if patchErr != nil {
+		return nil, fmt.Errorf("git patch-id failed: %w: %s", patchErr, strings.TrimSpace(patchStderr.String()))
+	}
+
+	groups := make(map[string][]string)
+	order := make([]string, 0)
+	scanner := bufio.NewScanner(&output)
+	for scanner.Scan() {
+		patchID, hash, ok := strings.Cut(scanner.Text(), " ")
+		if !ok {
+			continue
+		}
+		if _, credited := repo.Commits[hash]; !credited {
+			continue
+		}
+		if _, seen := groups[patchID]; !seen {
+			order = append(order, patchID)
+		}
+		groups[patchID] = append(groups[patchID], hash)
+	}
+
+	duplicates := make([]string, 0)
+	for _, patchID := range order {
+		group := groups[patchID]
+		duplicates = append(duplicates, group[:len(group)-1]...)
+	}
+	return duplicates, nil
+}
+
+// cherryPickDuplicates returns every commit recorded as cherry-picked from a
+// commit that is part of the analyzed history
+func cherryPickDuplicates(repo *types.Repository, revisions []string) ([]string, error) {
+	args := append([]string{"-C", repo.Path, "log", "-z", "--fixed-strings", "--grep=(cherry picked from commit ", "--format=%H%x00%B"}, revisions...)
+	output, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git log failed: %w", err)
+	}
+
+	duplicates := make([]string, 0)
+	resolved := make(map[string]string)
+	records := strings.Split(strings.TrimSuffix(string(output), "\x00"), "\x00")
+	for i := 0; i+1 < len(records); i += 2 {
+		hash := strings.TrimSpace(records[i])
+		for _, match := range cherryPickedFrom.FindAllStringSubmatch(records[i+1], -1) {
+			source := match[1]
+			// The ledger is keyed by full hashes; abbreviated ones, as some
+			// tools and hand-edited messages write, are resolved by git
+			if len(source) != len(hash) {
+				full, ok := resolved[source]
+				if !ok {
+					// Unknown or ambiguous abbreviations resolve to ""
+					full, _ = resolveCommit(repo.Path, source)
+					resolved[source] = full
+				}
+				source = full
+			}
+			if _, ok := repo.Commits[source]; ok && source != hash {
+				duplicates = append(duplicates, hash)
+				break
+			}
+		}
+	}
+	return duplicates, nil
+}
@@ -0,0 +1,73 @@
+package analyzer
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzer_Dedupe(t *testing.T) {
+	if !hasGit() {
+		t.Skip("Git not available, skipping integration test")
+	}
+
+	tempDir := createTestGitRepo(t)
+	commitAs(t, tempDir, "Fixer", "fixer@example.com", "fix.txt", "fix\n")
+	output, err := exec.Command("git", "-C", tempDir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		t.Fatalf("git rev-parse failed: %v", err)
+	}
+	fix := strings.TrimSpace(string(output))
+
+	// Distinct bases so the two cherry-picks do not end up as the same commit
+	for i, branch := range []string{"release-1", "release-2"} {
+		if err := runCmd(tempDir, "git", "checkout", "-q", "-b", branch, fix+strings.Repeat("~", i+1)); err != nil {
+			t.Fatalf("git checkout failed: %v", err)
+		}
+		if err := runCmd(tempDir, "git", "cherry-pick", "-x", fix); err != nil {
+			t.Fatalf("git cherry-pick failed: %v", err)
+		}
+	}
+
+	// A pick recorded by hand with an abbreviated hash
+	if err := runCmd(tempDir, "git", "checkout", "-q", "-b", "release-3", fix+"~2"); err != nil {
+		t.Fatalf("git checkout failed: %v", err)
+	}
+	if err := runCmd(tempDir, "git", "cherry-pick", "-n", fix); err != nil {
+		t.Fatalf("git cherry-pick failed: %v", err)
+	}
+	message := "Fix\n\n(cherry picked from commit " + fix[:10] + ")"
+	if err := runCmd(tempDir, "git", "commit", "-q", "--author", "Fixer <fixer@example.com>", "-m", message); err != nil {
+		t.Fatalf("git commit failed: %v", err)
+	}
+
+	tests := []struct {
+		dedupe    string
+		commits   int
+		collapsed int
+	}{
+		{"", 4, 0},
+		{DedupePatchID, 1, 3},
+		{DedupeCherryPick, 1, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run("mode="+tt.dedupe, func(t *testing.T) {
+			repo, err := NewAnalyzerWithOptions(Options{Dedupe: tt.dedupe}).AnalyzeRepository(tempDir)
+			if err != nil {
+				t.Fatalf("AnalyzeRepository failed: %v", err)
+			}
+
+			fixer := repo.Contributors["Fixer"]
+			if fixer == nil {
+				t.Fatal("Expected contributor 'Fixer'")
+			}
+			if fixer.CommitCount != tt.commits || fixer.LinesAdded != tt.commits || fixer.CollapsedCommits != tt.collapsed {
+				t.Errorf("Expected %d commits and %d collapsed, got %+v", tt.commits, tt.collapsed, fixer)
+			}
+			if testUser := repo.Contributors["Test User"]; testUser.CommitCount != 2 || testUser.CollapsedCommits != 0 {
+				t.Errorf("Expected unrelated commits to be kept, got %+v", testUser)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package analyzer
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// transliterations spell lowercase letters that have no canonical
+// decomposition, or that are not Latin at all, with ASCII letters. They are
+// consulted before the canonical decomposition so that e.g. Cyrillic "й"
+// becomes "y" rather than losing its breve and turning into "i".
+var transliterations = map[rune]string{
+	// Latin letters with strokes, ligatures and other special forms
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'ł': "l", 'đ': "d", 'ð': "d", 'þ': "th",
+	'ı': "i", 'ħ': "h", 'ŀ': "l", 'ŧ': "t", 'ĸ': "k", 'ŋ': "ng", 'ſ': "s", 'ĳ': "ij",
+	'ƀ': "b", 'ƈ': "c", 'ɖ': "d", 'ƒ': "f", 'ɠ': "g", 'ƙ': "k", 'ƚ': "l", 'ɲ': "n",
+	'ƥ': "p", 'ʂ': "s", 'ƭ': "t", 'ʋ': "v", 'ƴ': "y", 'ƶ': "z", 'ǝ': "e", 'ə': "e",
+
+	// Cyrillic (Russian, Ukrainian, Belarusian, Serbian, Macedonian, Bulgarian)
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya", 'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g", 'ў': "u", 'ђ': "dj", 'ј': "j",
+	'љ': "lj", 'њ': "nj", 'ћ': "c", 'џ': "dz", 'ѓ': "gj", 'ќ': "kj", 'ѕ': "dz",
+
+	// Greek
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i", 'θ': "th",
+	'ι': "i", 'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x", 'ο': "o", 'π': "p",
+	'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t", 'υ': "y", 'φ': "f", 'χ': "ch", 'ψ': "ps",
+	'ω': "o",
+}
+
+// foldName lowercases name, removes diacritics and transliterates special and
+// non-Latin letters, so spellings such as "Łukasz", "Lukasz" and "ŁUKASZ"
+// fold to the same string. Diacritics are removed by decomposing every letter
+// into its canonical form (NFD) and dropping the nonspacing marks, which also
+// covers input that is already decomposed.
+func foldName(name string) string {
+	var builder strings.Builder
+	builder.Grow(len(name))
+
+	var decomposed []byte
+	for _, r := range strings.ToLower(name) {
+		if latin, ok := transliterations[r]; ok {
+			builder.WriteString(latin)
+			continue
+		}
+		decomposed = norm.NFD.AppendString(decomposed[:0], string(r))
+		for _, part := range string(decomposed) {
+			if unicode.Is(unicode.Mn, part) {
+				continue
+			}
+			if latin, ok := transliterations[part]; ok {
+				builder.WriteString(latin)
+				continue
+			}
+			builder.WriteRune(part)
+		}
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,67 @@
+package analyzer
+
+import "testing"
+
+func TestFoldName(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"polish stroke", "Łukasz Wróbel", "lukasz wrobel"},
+		{"polish ogonek and acute", "Michał Żółć", "michal zolc"},
+		{"danish and norwegian", "Søren Kjærgaard", "soren kjaergaard"},
+		{"swedish ring", "Åsa Öberg", "asa oberg"},
+		{"german sharp s", "Strauß", "strauss"},
+		{"german capital sharp s", "STRAUẞ", "strauss"},
+		{"hungarian double acute", "Ősz Bűvös", "osz buvos"},
+		{"icelandic thorn and eth", "Þórður Guðmundsson", "thordur gudmundsson"},
+		{"turkish dotless i", "Işık", "isik"},
+		{"turkish dotted capital i", "İlker", "ilker"},
+		{"croatian d with stroke", "Đurđević", "durdevic"},
+		{"vietnamese stacked marks", "Nguyễn Thị Ánh", "nguyen thi anh"},
+		{"vietnamese horn and dot below", "Nguyễn Văn Hiệp Phở", "nguyen van hiep pho"},
+		{"latin extended-b", "Ǹǖǩǿ Ȑȕȟ Ǡ", "nuko ruh a"},
+		{"latin extended additional", "Ḿaṙḱ Ẓḁỳ", "mark zay"},
+		{"cyrillic extended", "Ӝӧӂ Ӓӥ", "zhozh ai"},
+		{"greek extended", "Ἀθῆναι Ὀδυσσεύς", "athinai odysseys"},
+		{"russian", "Дмитрий Шостакович", "dmitriy shostakovich"},
+		{"russian yo and soft sign", "Фёдор Ильич", "fedor ilich"},
+		{"ukrainian", "Олексій Їжак", "oleksiy yizhak"},
+		{"serbian", "Ђорђе Љубић", "djordje ljubic"},
+		{"greek with tonos", "Γιώργος Παπαδόπουλος", "giorgos papadopoylos"},
+		{"greek final sigma", "Νίκος", "nikos"},
+		{"decomposed input", "José García", "jose garcia"},
+		{"ascii unchanged", "John Doe 42", "john doe 42"},
+		{"cjk passes through", "王伟", "王伟"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := foldName(tt.input)
+			if result != tt.expected {
+				t.Errorf("foldName(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNameNormalizer_InternationalVariants(t *testing.T) {
+	normalizer := NewNameNormalizer()
+
+	groups := map[string][]string{
+		"lukaszwrobel":    {"Łukasz Wróbel", "Lukasz Wrobel", "łukasz.wróbel", "Wróbel, Łukasz"},
+		"sorenkjaergaard": {"Søren Kjærgaard", "Soren Kjaergaard", "SØREN KJÆRGAARD"},
+		"ivanpetrov":      {"Иван Петров", "Ivan Petrov", "ИВАН ПЕТРОВ"},
+		"zoltanoszi":      {"Zoltán Őszi", "Zoltan Oszi", "zoltan.oszi"},
+	}
+
+	for expected, variations := range groups {
+		for _, variation := range variations {
+			result := normalizer.NormalizeName(variation)
+			if result != expected {
+				t.Errorf("NormalizeName(%q) = %q, want %q", variation, result, expected)
+			}
+		}
+	}
+}
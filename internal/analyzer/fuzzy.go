@@ -0,0 +1,240 @@
+package analyzer
+
+import (
+	"strings"
+
+	"ganalyzer/pkg/types"
+)
+
+const (
+	// Reasons recorded on fuzzy merges
+	reasonSharedEmail  = "shared email"
+	reasonInitials     = "initials"
+	reasonEditDistance = "edit distance"
+
+	// Confidence assigned to the non-metric matches
+	sharedEmailConfidence = 1.0
+	initialsConfidence    = 0.9
+
+	// Names shorter than this are too ambiguous for edit distance
+	minFuzzyNameLength = 5
+)
+
+// fuzzyMatch is the best evidence found that two contributors are one person
+type fuzzyMatch struct {
+	confidence float64
+	reason     string
+	// abbreviatedX and abbreviatedY mark which side used initials in an initials match
+	abbreviatedX bool
+	abbreviatedY bool
+}
+
+// fuzzyEdge is a match above the threshold between two contributor keys
+type fuzzyEdge struct {
+	keyX, keyY string
+	match      fuzzyMatch
+}
+
+// clusterFuzzy merges contributors whose similarity reaches the threshold.
+// Matching is transitive: if A matches B and B matches C, all three merge.
+// Initials are only trusted when they expand to a single contributor, so
+// "J. Doe" is left alone when both "John Doe" and "Jane Doe" exist. Each
+// merged contributor is recorded on the survivor with the confidence of its
+// strongest match.
+func (a *Analyzer) clusterFuzzy(gs *types.GlobalStats) {
+	keys := sortedContributorKeys(gs)
+	edges := make([]fuzzyEdge, 0)
+	expansions := make(map[string]int)
+
+	for i, keyX := range keys {
+		for _, keyY := range keys[i+1:] {
+			match := a.compareContributors(gs.Contributors[keyX], gs.Contributors[keyY])
+			if match.confidence < a.fuzzyThreshold {
+				continue
+			}
+			edges = append(edges, fuzzyEdge{keyX: keyX, keyY: keyY, match: match})
+			if match.abbreviatedX {
+				expansions[keyX]++
+			}
+			if match.abbreviatedY {
+				expansions[keyY]++
+			}
+		}
+	}
+
+	groups := newKeyGroups(keys, a.conflictVeto(gs))
+	best := make(map[string]fuzzyMatch)
+	for _, edge := range edges {
+		if (edge.match.abbreviatedX && expansions[edge.keyX] > 1) || (edge.match.abbreviatedY && expansions[edge.keyY] > 1) {
+			continue
+		}
+		if !groups.union(edge.keyX, edge.keyY) {
+			continue
+		}
+		for _, key := range []string{edge.keyX, edge.keyY} {
+			if edge.match.confidence > best[key].confidence {
+				best[key] = edge.match
+			}
+		}
+	}
+
+	for _, group := range groups.sets(keys) {
+		if len(group) < 2 {
+			continue
+		}
+		target := strongestKey(gs, group)
+		for _, key := range group {
+			if key == target {
+				continue
+			}
+			merge := types.IdentityMerge{
+				Name:       gs.Contributors[key].Name,
+				Confidence: best[key].confidence,
+				Reason:     best[key].reason,
+			}
+			gs.MergeContributors(target, key)
+			gs.Contributors[target].Merges = append(gs.Contributors[target].Merges, merge)
+		}
+	}
+}
+
+// compareContributors scores how likely two contributors are the same person
+func (a *Analyzer) compareContributors(x, y *types.ContributorStats) fuzzyMatch {
+	if sharesEmail(x, y) {
+		return fuzzyMatch{confidence: sharedEmailConfidence, reason: reasonSharedEmail}
+	}
+
+	var best fuzzyMatch
+	for _, nameX := range contributorNames(x) {
+		tokensX := a.normalizer.tokens(nameX)
+		for _, nameY := range contributorNames(y) {
+			tokensY := a.normalizer.tokens(nameY)
+			if ok, abbreviatedX, abbreviatedY := matchesInitials(tokensX, tokensY); ok && initialsConfidence > best.confidence {
+				best = fuzzyMatch{
+					confidence:   initialsConfidence,
+					reason:       reasonInitials,
+					abbreviatedX: abbreviatedX,
+					abbreviatedY: abbreviatedY,
+				}
+			}
+			if score := nameSimilarity(strings.Join(tokensX, ""), strings.Join(tokensY, "")); score > best.confidence {
+				best = fuzzyMatch{confidence: score, reason: reasonEditDistance}
+			}
+		}
+	}
+	return best
+}
+
+func contributorNames(stats *types.ContributorStats) []string {
+	return append([]string{stats.Name}, stats.Aliases...)
+}
+
+// sharesEmail reports whether both contributors used a common personal address.
+// Generic no-reply addresses are shared by unrelated people and are ignored.
+func sharesEmail(x, y *types.ContributorStats) bool {
+	emails := make(map[string]bool)
+	for _, email := range contributorEmails(x) {
+		emails[strings.ToLower(email)] = true
+	}
+	for _, email := range contributorEmails(y) {
+		email = strings.ToLower(email)
+		if emails[email] && !isGenericEmail(email) {
+			return true
+		}
+	}
+	return false
+}
+
+func contributorEmails(stats *types.ContributorStats) []string {
+	if len(stats.Emails) == 0 && stats.Email != "" {
+		return []string{stats.Email}
+	}
+	return stats.Emails
+}
+
+func isGenericEmail(email string) bool {
+	local, _, _ := strings.Cut(email, "@")
+	switch local {
+	case "noreply", "no-reply", "nobody", "root", "unknown", "none":
+		return true
+	default:
+		return false
+	}
+}
+
+// matchesInitials reports whether two names have the same number of words and
+// every word pair is equal or an initial of the other, with at least one full
+// word in common ("J. Doe" and "John Doe", but not "J. D." and "John Doe").
+// It also reports which of the names used initials.
+func matchesInitials(x, y []string) (ok, abbreviatedX, abbreviatedY bool) {
+	if len(x) != len(y) || len(x) < 2 {
+		return false, false, false
+	}
+
+	fullMatch := false
+	for i := range x {
+		switch {
+		case x[i] == y[i]:
+			if len([]rune(x[i])) > 1 {
+				fullMatch = true
+			}
+		case isInitialOf(x[i], y[i]):
+			abbreviatedX = true
+		case isInitialOf(y[i], x[i]):
+			abbreviatedY = true
+		default:
+			return false, false, false
+		}
+	}
+	if !fullMatch || (!abbreviatedX && !abbreviatedY) {
+		return false, false, false
+	}
+	return true, abbreviatedX, abbreviatedY
+}
+
+func isInitialOf(initial, word string) bool {
+	initialRunes := []rune(initial)
+	wordRunes := []rune(word)
+	return len(initialRunes) == 1 && len(wordRunes) > 1 && initialRunes[0] == wordRunes[0]
+}
+
+// nameSimilarity returns 1 - distance/length using the optimal string
+// alignment distance, so a swapped pair of letters costs a single edit
+func nameSimilarity(x, y string) float64 {
+	runesX, runesY := []rune(x), []rune(y)
+	longest := len(runesX)
+	if len(runesY) > longest {
+		longest = len(runesY)
+	}
+	if longest < minFuzzyNameLength || len(runesX) == 0 || len(runesY) == 0 {
+		return 0
+	}
+	return 1 - float64(osaDistance(runesX, runesY))/float64(longest)
+}
+
+// osaDistance computes the optimal string alignment (restricted
+// Damerau-Levenshtein) distance between x and y
+func osaDistance(x, y []rune) int {
+	rows := make([][]int, len(x)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(y)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+
+	for i := 1; i <= len(x); i++ {
+		for j := 1; j <= len(y); j++ {
+			cost := 1
+			if x[i-1] == y[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && x[i-1] == y[j-2] && x[i-2] == y[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(x)][len(y)]
+}
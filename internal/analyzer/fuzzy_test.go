@@ -0,0 +1,118 @@
+package analyzer
+
+import (
+	"math"
+	"testing"
+
+	"ganalyzer/pkg/types"
+)
+
+func TestOSADistance(t *testing.T) {
+	tests := []struct {
+		x, y     string
+		expected int
+	}{
+		{"johndoe", "johndoe", 0},
+		{"jonhdoe", "johndoe", 1},
+		{"johndoe", "johndo", 1},
+		{"johndoe", "janedoe", 3},
+		{"", "abc", 3},
+	}
+
+	for _, tt := range tests {
+		if result := osaDistance([]rune(tt.x), []rune(tt.y)); result != tt.expected {
+			t.Errorf("osaDistance(%q, %q) = %d, want %d", tt.x, tt.y, result, tt.expected)
+		}
+	}
+}
+
+func TestMatchesInitials(t *testing.T) {
+	tests := []struct {
+		x, y     []string
+		expected bool
+	}{
+		{[]string{"j", "doe"}, []string{"john", "doe"}, true},
+		{[]string{"john", "q", "public"}, []string{"john", "quincy", "public"}, true},
+		{[]string{"j", "d"}, []string{"john", "doe"}, false},
+		{[]string{"j", "smith"}, []string{"john", "doe"}, false},
+		{[]string{"john", "doe"}, []string{"john", "doe"}, false},
+		{[]string{"j", "doe"}, []string{"john", "michael", "doe"}, false},
+	}
+
+	for _, tt := range tests {
+		if result, _, _ := matchesInitials(tt.x, tt.y); result != tt.expected {
+			t.Errorf("matchesInitials(%v, %v) = %v, want %v", tt.x, tt.y, result, tt.expected)
+		}
+	}
+}
+
+func TestAnalyzer_ClusterFuzzy(t *testing.T) {
+	gs := types.NewGlobalStats()
+	repo := types.NewRepository("/repo")
+	repo.Contributors["John Doe"] = &types.ContributorStats{Name: "John Doe", Email: "john@acme.com", CommitCount: 20}
+	repo.Contributors["J. Doe"] = &types.ContributorStats{Name: "J. Doe", Email: "JOHN@acme.com", CommitCount: 3}
+	repo.Contributors["Jonh Doe"] = &types.ContributorStats{Name: "Jonh Doe", Email: "typo@acme.com", CommitCount: 1}
+	repo.Contributors["Jane Doe"] = &types.ContributorStats{Name: "Jane Doe", Email: "jane@acme.com", CommitCount: 7}
+	repo.Contributors["jdoe-laptop"] = &types.ContributorStats{Name: "jdoe-laptop", Email: "jd@home.net", CommitCount: 2}
+	repo.Contributors["Build Bot"] = &types.ContributorStats{Name: "Build Bot", Email: "noreply@acme.com", CommitCount: 5}
+	repo.Contributors["Release Bot"] = &types.ContributorStats{Name: "Release Bot", Email: "noreply@acme.com", CommitCount: 5}
+	gs.AddRepository(repo)
+
+	NewAnalyzerWithOptions(Options{FuzzyThreshold: 0.85}).ResolveIdentities(gs)
+
+	if len(gs.Contributors) != 5 {
+		t.Fatalf("Expected 5 contributors after clustering, got %d: %v", len(gs.Contributors), sortedContributorKeys(gs))
+	}
+
+	john := gs.Contributors["John Doe"]
+	if john == nil || john.CommitCount != 24 {
+		t.Fatalf("Expected John Doe with 24 commits, got %+v", john)
+	}
+
+	merges := make(map[string]types.IdentityMerge)
+	for _, merge := range john.Merges {
+		merges[merge.Name] = merge
+	}
+	if len(merges) != 2 {
+		t.Fatalf("Expected 2 recorded merges, got %+v", john.Merges)
+	}
+	if merges["J. Doe"].Reason != reasonSharedEmail || merges["J. Doe"].Confidence != 1 {
+		t.Errorf("Expected J. Doe merged by shared email with confidence 1, got %+v", merges["J. Doe"])
+	}
+	typo := merges["Jonh Doe"]
+	if typo.Reason != reasonEditDistance || math.Abs(typo.Confidence-6.0/7.0) > 1e-9 {
+		t.Errorf("Expected Jonh Doe merged by edit distance with confidence 6/7, got %+v", typo)
+	}
+
+	if gs.Contributors["Jane Doe"] == nil {
+		t.Error("Jane Doe should not be merged through the ambiguous initials of J. Doe")
+	}
+	if gs.Contributors["jdoe-laptop"] == nil {
+		t.Error("jdoe-laptop should stay separate")
+	}
+	if gs.Contributors["Build Bot"] == nil || gs.Contributors["Release Bot"] == nil {
+		t.Error("Contributors sharing only a no-reply address should stay separate")
+	}
+
+	if repo.Contributors["John Doe"].CommitCount != 24 {
+		t.Errorf("Expected repository entries merged too, got %+v", repo.Contributors["John Doe"])
+	}
+}
+
+func TestAnalyzer_ClusterFuzzyInitials(t *testing.T) {
+	gs := types.NewGlobalStats()
+	repo := types.NewRepository("/repo")
+	repo.Contributors["Alexander Hamilton"] = &types.ContributorStats{Name: "Alexander Hamilton", CommitCount: 4}
+	repo.Contributors["A. Hamilton"] = &types.ContributorStats{Name: "A. Hamilton", CommitCount: 2}
+	gs.AddRepository(repo)
+
+	NewAnalyzerWithOptions(Options{FuzzyThreshold: 0.9}).ResolveIdentities(gs)
+
+	alexander := gs.Contributors["Alexander Hamilton"]
+	if len(gs.Contributors) != 1 || alexander == nil {
+		t.Fatalf("Expected initials to merge, got %v", sortedContributorKeys(gs))
+	}
+	if len(alexander.Merges) != 1 || alexander.Merges[0].Reason != reasonInitials {
+		t.Errorf("Expected a single initials merge, got %+v", alexander.Merges)
+	}
+}
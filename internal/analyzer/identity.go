@@ -0,0 +1,171 @@
+package analyzer
+
+import (
+	"sort"
+	"strings"
+
+	"ganalyzer/pkg/types"
+)
+
+// Minimum number of words a name needs before word order is considered
+const minReorderTokens = 2
+
+// ResolveIdentities runs the identity stages that need the merged statistics
+// of all repositories. Call it once after every repository has been added.
+func (a *Analyzer) ResolveIdentities(gs *types.GlobalStats) {
+	if a.normalize {
+		a.mergeReorderedNames(gs)
+	}
+	if a.fuzzyThreshold > 0 {
+		a.clusterFuzzy(gs)
+	}
+	if a.bots != nil {
+		a.markBots(gs)
+	}
+}
+
+// mergeReorderedNames merges contributors whose names, or aliases, consist of
+// the same words in a different order ("Smith John" and "John Smith"). The
+// contributor with the most commits keeps its key and display name.
+func (a *Analyzer) mergeReorderedNames(gs *types.GlobalStats) {
+	keys := sortedContributorKeys(gs)
+	groups := newKeyGroups(keys, a.conflictVeto(gs))
+	owners := make(map[string]string)
+
+	for _, key := range keys {
+		contributor := gs.Contributors[key]
+		names := append([]string{contributor.Name}, contributor.Aliases...)
+		for _, name := range names {
+			signature := a.normalizer.TokenKey(name)
+			if strings.Count(signature, " ") < minReorderTokens-1 {
+				continue
+			}
+			if owner, ok := owners[signature]; ok {
+				groups.union(owner, key)
+			} else {
+				owners[signature] = key
+			}
+		}
+	}
+
+	mergeGroups(gs, groups.sets(keys))
+}
+
+// conflictVeto returns a check that keeps contributors separated by
+// never-merge rules out of the same group, or nil without rules
+func (a *Analyzer) conflictVeto(gs *types.GlobalStats) func(x, y string) bool {
+	if a.rules == nil {
+		return nil
+	}
+	return func(x, y string) bool {
+		return a.rules.conflict(gs.Contributors[x], gs.Contributors[y])
+	}
+}
+
+// sortedContributorKeys returns the global contributor keys in a stable order
+func sortedContributorKeys(gs *types.GlobalStats) []string {
+	keys := make([]string, 0, len(gs.Contributors))
+	for key := range gs.Contributors {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// mergeGroups merges every group of keys into its strongest member
+func mergeGroups(gs *types.GlobalStats, groups [][]string) {
+	for _, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+
+		target := strongestKey(gs, group)
+		for _, key := range group {
+			gs.MergeContributors(target, key)
+		}
+	}
+}
+
+// strongestKey returns the key with the most commits, ties broken by the
+// smallest key; group must be sorted
+func strongestKey(gs *types.GlobalStats, group []string) string {
+	target := group[0]
+	for _, key := range group[1:] {
+		if gs.Contributors[key].CommitCount > gs.Contributors[target].CommitCount {
+			target = key
+		}
+	}
+	return target
+}
+
+// keyGroups is a union-find over contributor keys. An optional veto keeps
+// two groups apart when any pair of their members must not be merged.
+type keyGroups struct {
+	parent  map[string]string
+	members map[string][]string
+	veto    func(x, y string) bool
+}
+
+func newKeyGroups(keys []string, veto func(x, y string) bool) *keyGroups {
+	groups := &keyGroups{
+		parent:  make(map[string]string, len(keys)),
+		members: make(map[string][]string, len(keys)),
+		veto:    veto,
+	}
+	for _, key := range keys {
+		groups.parent[key] = key
+		groups.members[key] = []string{key}
+	}
+	return groups
+}
+
+func (g *keyGroups) find(key string) string {
+	for g.parent[key] != key {
+		g.parent[key] = g.parent[g.parent[key]]
+		key = g.parent[key]
+	}
+	return key
+}
+
+// union joins the groups of a and b and reports whether they are now one group
+func (g *keyGroups) union(a, b string) bool {
+	rootA, rootB := g.find(a), g.find(b)
+	if rootA == rootB {
+		return true
+	}
+	if g.veto != nil {
+		for _, x := range g.members[rootA] {
+			for _, y := range g.members[rootB] {
+				if g.veto(x, y) {
+					return false
+				}
+			}
+		}
+	}
+
+	// Keep the smaller key as root so grouping does not depend on call order
+	if rootB < rootA {
+		rootA, rootB = rootB, rootA
+	}
+	g.parent[rootB] = rootA
+	g.members[rootA] = append(g.members[rootA], g.members[rootB]...)
+	delete(g.members, rootB)
+	return true
+}
+
+// sets returns the groups in order of their first key, each sorted
+func (g *keyGroups) sets(keys []string) [][]string {
+	index := make(map[string]int)
+	sets := make([][]string, 0)
+	for _, key := range keys {
+		root := g.find(key)
+		i, ok := index[root]
+		if !ok {
+			i = len(sets)
+			index[root] = i
+			sets = append(sets, nil)
+		}
+		sets[i] = append(sets[i], key)
+	}
+	return sets
+}
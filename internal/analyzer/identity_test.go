@@ -0,0 +1,63 @@
+package analyzer
+
+import (
+	"testing"
+
+	"ganalyzer/pkg/types"
+)
+
+func TestAnalyzer_ResolveIdentitiesMergesReorderedNames(t *testing.T) {
+	gs := types.NewGlobalStats()
+
+	repo1 := types.NewRepository("/repo1")
+	repo1.Contributors["johnsmith"] = &types.ContributorStats{
+		Name:        "John Smith",
+		CommitCount: 10,
+		Aliases:     []string{"Smith, John"},
+	}
+	repo1.Contributors["janedoe"] = &types.ContributorStats{Name: "Jane Doe", CommitCount: 4}
+	gs.AddRepository(repo1)
+
+	repo2 := types.NewRepository("/repo2")
+	repo2.Contributors["smithjohn"] = &types.ContributorStats{Name: "Smith John", CommitCount: 3}
+	repo2.Contributors["doe"] = &types.ContributorStats{Name: "Doe", CommitCount: 1}
+	gs.AddRepository(repo2)
+
+	NewAnalyzerWithNormalization(true).ResolveIdentities(gs)
+
+	if len(gs.Contributors) != 3 {
+		t.Fatalf("Expected 3 contributors after merging, got %d", len(gs.Contributors))
+	}
+
+	john := gs.Contributors["johnsmith"]
+	if john == nil || john.CommitCount != 13 {
+		t.Fatalf("Expected John Smith with 13 commits, got %+v", john)
+	}
+	if john.Name != "John Smith" {
+		t.Errorf("Expected display name of the larger contributor, got %s", john.Name)
+	}
+	if len(john.Aliases) != 2 {
+		t.Errorf("Expected aliases from both orders, got %v", john.Aliases)
+	}
+
+	if _, ok := repo2.Contributors["smithjohn"]; ok {
+		t.Error("Expected repository entry to be re-keyed")
+	}
+	if moved := repo2.Contributors["johnsmith"]; moved == nil || moved.CommitCount != 3 {
+		t.Errorf("Expected per-repository count preserved under the merged key, got %+v", moved)
+	}
+}
+
+func TestAnalyzer_ResolveIdentitiesWithoutNormalization(t *testing.T) {
+	gs := types.NewGlobalStats()
+	repo := types.NewRepository("/repo")
+	repo.Contributors["John Smith"] = &types.ContributorStats{Name: "John Smith", CommitCount: 1}
+	repo.Contributors["Smith John"] = &types.ContributorStats{Name: "Smith John", CommitCount: 1}
+	gs.AddRepository(repo)
+
+	NewAnalyzer().ResolveIdentities(gs)
+
+	if len(gs.Contributors) != 2 {
+		t.Errorf("Expected names to stay separate without normalization, got %d contributors", len(gs.Contributors))
+	}
+}
@@ -0,0 +1,189 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// OtherLanguage is the language of files the table does not know
+const OtherLanguage = "Other"
+
+// defaultFilenames maps whole file names to languages; they take precedence
+// over extensions
+var defaultFilenames = map[string]string{
+	"Dockerfile":     "Dockerfile",
+	"Containerfile":  "Dockerfile",
+	"Makefile":       "Makefile",
+	"GNUmakefile":    "Makefile",
+	"CMakeLists.txt": "CMake",
+	"Jenkinsfile":    "Groovy",
+	"Vagrantfile":    "Ruby",
+	"Gemfile":        "Ruby",
+	"Rakefile":       "Ruby",
+	"BUILD":          "Starlark",
+	"BUILD.bazel":    "Starlark",
+	"WORKSPACE":      "Starlark",
+	"go.mod":         "Go",
+	"go.work":        "Go",
+}
+
+// defaultExtensions maps lower-case file extensions to languages
+var defaultExtensions = map[string]string{
+	".go":      "Go",
+	".tf":      "Terraform",
+	".tfvars":  "Terraform",
+	".hcl":     "HCL",
+	".yaml":    "YAML",
+	".yml":     "YAML",
+	".json":    "JSON",
+	".toml":    "TOML",
+	".xml":     "XML",
+	".md":      "Markdown",
+	".rst":     "reStructuredText",
+	".txt":     "Text",
+	".py":      "Python",
+	".js":      "JavaScript",
+	".mjs":     "JavaScript",
+	".cjs":     "JavaScript",
+	".jsx":     "JavaScript",
+	".ts":      "TypeScript",
+	".tsx":     "TypeScript",
+	".vue":     "Vue",
+	".svelte":  "Svelte",
+	".html":    "HTML",
+	".htm":     "HTML",
+	".css":     "CSS",
+	".scss":    "SCSS",
+	".sass":    "SCSS",
+	".less":    "Less",
+	".java":    "Java",
+	".kt":      "Kotlin",
+	".kts":     "Kotlin",
+	".scala":   "Scala",
+	".groovy":  "Groovy",
+	".gradle":  "Groovy",
+	".c":       "C",
+	".h":       "C",
+	".cc":      "C++",
+	".cpp":     "C++",
+	".cxx":     "C++",
+	".hh":      "C++",
+	".hpp":     "C++",
+	".cs":      "C#",
+	".m":       "Objective-C",
+	".mm":      "Objective-C",
+	".swift":   "Swift",
+	".rs":      "Rust",
+	".rb":      "Ruby",
+	".php":     "PHP",
+	".pl":      "Perl",
+	".pm":      "Perl",
+	".lua":     "Lua",
+	".r":       "R",
+	".dart":    "Dart",
+	".ex":      "Elixir",
+	".exs":     "Elixir",
+	".erl":     "Erlang",
+	".hs":      "Haskell",
+	".clj":     "Clojure",
+	".sh":      "Shell",
+	".bash":    "Shell",
+	".zsh":     "Shell",
+	".ps1":     "PowerShell",
+	".sql":     "SQL",
+	".proto":   "Protocol Buffers",
+	".graphql": "GraphQL",
+	".gql":     "GraphQL",
+	".bzl":     "Starlark",
+	".mk":      "Makefile",
+}
+
+// LanguageTable maps file paths to languages by file name or extension. The
+// built-in table can be extended from JSON:
+//
+//	{
+//	  "extensions": {".jsonnet": "Jsonnet", "libsonnet": "Jsonnet"},
+//	  "filenames": {"Tiltfile": "Starlark"}
+//	}
+//
+// Extensions match case-insensitively and the leading dot is optional; file
+// names match the base name exactly. Entries override built-in ones.
+type LanguageTable struct {
+	Extensions map[string]string `json:"extensions"`
+	Filenames  map[string]string `json:"filenames"`
+}
+
+// NewLanguageTable returns the built-in language table
+func NewLanguageTable() *LanguageTable {
+	table := &LanguageTable{
+		Extensions: make(map[string]string, len(defaultExtensions)),
+		Filenames:  make(map[string]string, len(defaultFilenames)),
+	}
+	for extension, language := range defaultExtensions {
+		table.Extensions[extension] = language
+	}
+	for name, language := range defaultFilenames {
+		table.Filenames[name] = language
+	}
+	return table
+}
+
+// LoadLanguageTable reads a JSON file extending the built-in language table
+func LoadLanguageTable(path string) (*LanguageTable, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open language table: %w", err)
+	}
+	defer file.Close()
+
+	table, err := ParseLanguageTable(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse language table %s: %w", path, err)
+	}
+	return table, nil
+}
+
+// ParseLanguageTable decodes entries and adds them to the built-in table
+func ParseLanguageTable(r io.Reader) (*LanguageTable, error) {
+	extra := &LanguageTable{}
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(extra); err != nil {
+		return nil, err
+	}
+
+	table := NewLanguageTable()
+	for extension, language := range extra.Extensions {
+		extension = strings.ToLower(strings.TrimSpace(extension))
+		if extension == "" || extension == "." || strings.TrimSpace(language) == "" {
+			return nil, fmt.Errorf("extension %q needs a name and a language", extension)
+		}
+		if !strings.HasPrefix(extension, ".") {
+			extension = "." + extension
+		}
+		table.Extensions[extension] = strings.TrimSpace(language)
+	}
+	for name, language := range extra.Filenames {
+		if strings.TrimSpace(name) == "" || strings.Contains(name, "/") || strings.TrimSpace(language) == "" {
+			return nil, fmt.Errorf("file name %q needs a base name and a language", name)
+		}
+		table.Filenames[name] = strings.TrimSpace(language)
+	}
+	return table, nil
+}
+
+// Language returns the language of the file at filePath, or OtherLanguage
+func (t *LanguageTable) Language(filePath string) string {
+	name := path.Base(filePath)
+	if language, ok := t.Filenames[name]; ok {
+		return language
+	}
+	if language, ok := t.Extensions[strings.ToLower(path.Ext(name))]; ok {
+		return language
+	}
+	return OtherLanguage
+}
@@ -0,0 +1,147 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"ganalyzer/pkg/types"
+)
+
+func TestLanguageTable_Language(t *testing.T) {
+	table := NewLanguageTable()
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"main.go", "Go"},
+		{"infra/modules/vpc/main.tf", "Terraform"},
+		{".github/workflows/ci.yml", "YAML"},
+		{"deploy/values.YAML", "YAML"},
+		{"build/Dockerfile", "Dockerfile"},
+		{"go.mod", "Go"},
+		{"LICENSE", OtherLanguage},
+		{".gitignore", OtherLanguage},
+		{"archive.tar.gz", OtherLanguage},
+	}
+	for _, tt := range tests {
+		if got := table.Language(tt.path); got != tt.want {
+			t.Errorf("Language(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParseLanguageTable(t *testing.T) {
+	input := `{
+		"extensions": {"jsonnet": "Jsonnet", ".TF": "OpenTofu"},
+		"filenames": {"Tiltfile": "Starlark"}
+	}`
+	table, err := ParseLanguageTable(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseLanguageTable failed: %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"lib/app.jsonnet", "Jsonnet"},
+		{"main.tf", "OpenTofu"},
+		{"Tiltfile", "Starlark"},
+		{"main.go", "Go"},
+	}
+	for _, tt := range tests {
+		if got := table.Language(tt.path); got != tt.want {
+			t.Errorf("Language(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	// Extending a table leaves the built-in one alone
+	if got := NewLanguageTable().Language("main.tf"); got != "Terraform" {
+		t.Errorf("Expected the built-in table to be unchanged, got %q", got)
+	}
+}
+
+func TestParseLanguageTable_Invalid(t *testing.T) {
+	inputs := []string{
+		`{"extensions": {".x": ""}}`,
+		`{"extensions": {".": "Dot"}}`,
+		`{"filenames": {"ci/Jenkinsfile": "Groovy"}}`,
+		`{"languages": {}}`,
+		`not json`,
+	}
+	for _, input := range inputs {
+		if _, err := ParseLanguageTable(strings.NewReader(input)); err == nil {
+			t.Errorf("ParseLanguageTable(%s) succeeded, want an error", input)
+		}
+	}
+}
+
+func TestAnalyzer_Languages(t *testing.T) {
+	if !hasGit() {
+		t.Skip("Git not available, skipping integration test")
+	}
+
+	tempDir := createTestGitRepo(t)
+	commitAs(t, tempDir, "Alice", "alice@example.com", "main.go", "package main\n\nfunc main() {}\n")
+	if err := os.WriteFile(filepath.Join(tempDir, "main.tf"), []byte("a = 1\nb = 2\nc = 3\n"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "ci.yml"), []byte("on: push\n"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if err := runCmd(tempDir, "git", "add", "main.tf", "ci.yml"); err != nil {
+		t.Fatalf("git add failed: %v", err)
+	}
+	trailers := "Co-authored-by: Bob <bob@example.com>"
+	if err := runCmd(tempDir, "git", "commit", "--author", "Alice <alice@example.com>", "-m", "Add infra", "-m", trailers); err != nil {
+		t.Fatalf("git commit failed: %v", err)
+	}
+
+	tests := []struct {
+		policy string
+		alice  map[string]types.LanguageLines
+		bob    map[string]types.LanguageLines
+	}{
+		{"", map[string]types.LanguageLines{
+			"Go":        {LinesAdded: 3},
+			"Terraform": {LinesAdded: 3},
+			"YAML":      {LinesAdded: 1},
+		}, nil},
+		{CoAuthorsSplit, map[string]types.LanguageLines{
+			"Go":        {LinesAdded: 3},
+			"Terraform": {LinesAdded: 2},
+			"YAML":      {LinesAdded: 1},
+		}, map[string]types.LanguageLines{
+			"Terraform": {LinesAdded: 1},
+		}},
+	}
+	for _, tt := range tests {
+		repo, err := NewAnalyzerWithOptions(Options{CoAuthors: tt.policy}).AnalyzeRepository(tempDir)
+		if err != nil {
+			t.Fatalf("AnalyzeRepository failed: %v", err)
+		}
+
+		alice := repo.Contributors["Alice"]
+		if !reflect.DeepEqual(alice.Languages, tt.alice) {
+			t.Errorf("co-authors %q: expected Alice's languages %v, got %v", tt.policy, tt.alice, alice.Languages)
+		}
+		if bob := repo.Contributors["Bob"]; bob != nil && !reflect.DeepEqual(bob.Languages, tt.bob) {
+			t.Errorf("co-authors %q: expected Bob's languages %v, got %v", tt.policy, tt.bob, bob.Languages)
+		}
+
+		// The language split always adds up to the line counts
+		for key, stats := range repo.Contributors {
+			added := 0
+			for _, lines := range stats.Languages {
+				added += lines.LinesAdded
+			}
+			if added != stats.LinesAdded {
+				t.Errorf("co-authors %q: %s has %d lines added but %d across languages", tt.policy, key, stats.LinesAdded, added)
+			}
+		}
+	}
+}
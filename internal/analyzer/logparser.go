@@ -0,0 +1,201 @@
+package analyzer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"ganalyzer/pkg/types"
+)
+
+const (
+	// recordMarker starts every commit header; git never emits it in a numstat entry
+	recordMarker = '\x1e'
+	// fieldSeparator terminates header fields and numstat entries when git runs with -z
+	fieldSeparator = '\x00'
+	// Number of tab-separated columns in a numstat entry: added, deleted, path
+	numstatColumns = 3
+	// trailerSeparator separates the values of repeated trailers in a header field
+	trailerSeparator = "\x1f"
+)
+
+// logFormat is the --format passed to git log. Each header field is
+// NUL-terminated so author names containing tabs or newlines stay intact.
+// The last field lists Co-authored-by trailer values (matched case-insensitively).
+const logFormat = "%x1e%H%x00%aN%x00%aE%x00%at%x00%s%x00%(trailers:key=Co-authored-by,valueonly,separator=%x1f)%x00"
+
+// headerFields is the number of NUL-terminated fields logFormat produces
+const headerFields = 6
+
+// commitRecord is a single commit read from the git log stream
+type commitRecord struct {
+	Hash        string
+	AuthorName  string
+	AuthorEmail string
+	// AuthorTime is the author date; zero when git reported none
+	AuthorTime time.Time
+	Subject    string
+	// CoAuthors are the identities named in Co-authored-by trailers, as written
+	CoAuthors []types.Identity
+	Files     []fileStat
+}
+
+// fileStat is one numstat entry of a commit
+type fileStat struct {
+	Path string
+	// OldPath is the path before a rename or copy, empty otherwise
+	OldPath string
+	Added   int
+	Deleted int
+	// Binary is set when git reports "-" instead of line counts
+	Binary bool
+}
+
+// logArgs returns the git log arguments understood by parseLog
+func logArgs() []string {
+	return []string{"log", "-z", "--numstat", "--format=" + logFormat}
+}
+
+// parseLog reads `git log -z --numstat --format=logFormat` output incrementally
+// and calls fn once per commit, in the order git emits them
+func parseLog(r io.Reader, fn func(*commitRecord) error) error {
+	reader := bufio.NewReader(r)
+	var current *commitRecord
+
+	flush := func() error {
+		if current == nil {
+			return nil
+		}
+		record := current
+		current = nil
+		return fn(record)
+	}
+
+	for {
+		token, err := readToken(reader)
+		if err == io.EOF && token == "" {
+			return flush()
+		}
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("failed to read git log output: %w", err)
+		}
+
+		// Header fields, and numstat entries after a commit header, are preceded by a newline
+		token = strings.TrimPrefix(token, "\n")
+
+		switch {
+		case token == "":
+			// Terminator of the format or separator between commits
+		case token[0] == recordMarker:
+			if ferr := flush(); ferr != nil {
+				return ferr
+			}
+			current, err = readHeader(reader, token[1:])
+			if err != nil {
+				return err
+			}
+		case current != nil:
+			stat, ok, serr := parseNumstat(reader, token)
+			if serr != nil {
+				return serr
+			}
+			if ok {
+				current.Files = append(current.Files, stat)
+			}
+		}
+
+		if err == io.EOF {
+			return flush()
+		}
+	}
+}
+
+func readToken(reader *bufio.Reader) (string, error) {
+	token, err := reader.ReadString(fieldSeparator)
+	return strings.TrimSuffix(token, string(fieldSeparator)), err
+}
+
+func readHeader(reader *bufio.Reader, hash string) (*commitRecord, error) {
+	fields := make([]string, 0, headerFields-1)
+	for len(fields) < headerFields-1 {
+		field, err := readToken(reader)
+		if err != nil {
+			return nil, fmt.Errorf("truncated git log header for commit %s: %w", hash, err)
+		}
+		fields = append(fields, field)
+	}
+
+	return &commitRecord{
+		Hash:        hash,
+		AuthorName:  strings.TrimSpace(fields[0]),
+		AuthorEmail: strings.TrimSpace(fields[1]),
+		AuthorTime:  parseUnixTime(fields[2]),
+		Subject:     fields[3],
+		CoAuthors:   parseCoAuthors(fields[4]),
+	}, nil
+}
+
+// parseUnixTime parses a %at timestamp, returning the zero time for anything else
+func parseUnixTime(field string) time.Time {
+	seconds, err := strconv.ParseInt(strings.TrimSpace(field), 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0).UTC()
+}
+
+// parseCoAuthors splits trailer values of the form "Name <email>"
+func parseCoAuthors(field string) []types.Identity {
+	var coAuthors []types.Identity
+	for _, value := range strings.Split(field, trailerSeparator) {
+		name, email, _ := strings.Cut(value, "<")
+		coAuthor := types.Identity{
+			Name:  strings.TrimSpace(name),
+			Email: strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(email), ">")),
+		}
+		if coAuthor.Name != "" {
+			coAuthors = append(coAuthors, coAuthor)
+		}
+	}
+	return coAuthors
+}
+
+// parseNumstat parses "added\tdeleted\tpath". For renames and copies git -z
+// leaves the path empty and emits the old and new paths as the next two tokens.
+func parseNumstat(reader *bufio.Reader, token string) (fileStat, bool, error) {
+	parts := strings.SplitN(token, "\t", numstatColumns)
+	if len(parts) != numstatColumns {
+		return fileStat{}, false, nil
+	}
+
+	stat := fileStat{Path: parts[2]}
+	if stat.Path == "" {
+		oldPath, err := readToken(reader)
+		if err != nil {
+			return fileStat{}, false, fmt.Errorf("truncated rename entry in git log output: %w", err)
+		}
+		newPath, err := readToken(reader)
+		if err != nil && err != io.EOF {
+			return fileStat{}, false, fmt.Errorf("truncated rename entry in git log output: %w", err)
+		}
+		stat.Path, stat.OldPath = newPath, oldPath
+	}
+
+	if parts[0] == "-" && parts[1] == "-" {
+		stat.Binary = true
+		return stat, true, nil
+	}
+
+	added, err1 := strconv.Atoi(parts[0])
+	deleted, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil {
+		return fileStat{}, false, nil
+	}
+
+	stat.Added = added
+	stat.Deleted = deleted
+	return stat, true, nil
+}
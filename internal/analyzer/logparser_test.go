@@ -0,0 +1,88 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLog(t *testing.T) {
+	// Shaped like `git log -z --numstat --format=logFormat`: an empty commit,
+	// a commit with a binary file and a rename, and a pair-programmed commit
+	input := "\x1eaaa\x00Tab\tName\x00tab@example.com\x001700000000\x00Empty commit\x00\x00\x00" +
+		"\x1ebbb\x0012\t3\tlooks-like-numstat\x00odd@example.com\x00\x00\x00\x00\x00" +
+		"\n-\t-\tlogo.png\x001\t0\t\x00old.txt\x00new.txt\x00" +
+		"\x1eccc\x00Plain Name\x00plain@example.com\x001700003600\x00Add main\x00Bob B <bob@example.com>\x1fCarol\x00\x00" +
+		"\n2\t1\tmain.go\x0010\t0\tREADME.md\x00"
+
+	var commits []*commitRecord
+	err := parseLog(strings.NewReader(input), func(commit *commitRecord) error {
+		commits = append(commits, commit)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("parseLog failed: %v", err)
+	}
+
+	if len(commits) != 3 {
+		t.Fatalf("Expected 3 commits, got %d", len(commits))
+	}
+
+	if commits[0].Hash != "aaa" || commits[0].AuthorName != "Tab\tName" || commits[0].Subject != "Empty commit" || len(commits[0].Files) != 0 {
+		t.Errorf("Unexpected first commit: %+v", commits[0])
+	}
+	if !commits[0].AuthorTime.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("Unexpected author time of first commit: %v", commits[0].AuthorTime)
+	}
+
+	second := commits[1]
+	if second.AuthorName != "12\t3\tlooks-like-numstat" || second.AuthorEmail != "odd@example.com" {
+		t.Errorf("Unexpected author of second commit: %q <%q>", second.AuthorName, second.AuthorEmail)
+	}
+	if !second.AuthorTime.IsZero() {
+		t.Errorf("Expected no author time for second commit, got %v", second.AuthorTime)
+	}
+	if len(second.Files) != 2 {
+		t.Fatalf("Expected 2 files in second commit, got %d: %+v", len(second.Files), second.Files)
+	}
+	if !second.Files[0].Binary || second.Files[0].Path != "logo.png" {
+		t.Errorf("Expected binary logo.png, got %+v", second.Files[0])
+	}
+	if second.Files[1].Path != "new.txt" || second.Files[1].OldPath != "old.txt" || second.Files[1].Added != 1 {
+		t.Errorf("Expected rename to new.txt with 1 line added, got %+v", second.Files[1])
+	}
+
+	third := commits[2]
+	if third.Hash != "ccc" || third.Subject != "Add main" || len(third.Files) != 2 {
+		t.Fatalf("Unexpected third commit: %+v", third)
+	}
+	if len(third.CoAuthors) != 2 || third.CoAuthors[0].Email != "bob@example.com" || third.CoAuthors[1].Name != "Carol" {
+		t.Errorf("Unexpected co-authors of third commit: %+v", third.CoAuthors)
+	}
+	if third.Files[0].Added != 2 || third.Files[0].Deleted != 1 || third.Files[1].Added != 10 {
+		t.Errorf("Unexpected line counts in third commit: %+v", third.Files)
+	}
+}
+
+func TestParseLog_Empty(t *testing.T) {
+	calls := 0
+	err := parseLog(strings.NewReader(""), func(*commitRecord) error {
+		calls++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("parseLog failed: %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("Expected no commits, got %d", calls)
+	}
+}
+
+func TestParseLog_TruncatedHeader(t *testing.T) {
+	err := parseLog(strings.NewReader("\x1eaaa\x00Only Name"), func(*commitRecord) error {
+		return nil
+	})
+	if err == nil {
+		t.Error("Expected error for truncated header, got nil")
+	}
+}
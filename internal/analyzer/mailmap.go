@@ -0,0 +1,337 @@
+package analyzer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"ganalyzer/pkg/types"
+)
+
+// mailmapFilePermissions is used when creating a .mailmap in a repository
+const mailmapFilePermissions = 0o644
+
+// MailmapEntry is one line of a mailmap file. Empty proper fields keep the
+// commit's value; an empty CommitName matches any name with CommitEmail.
+type MailmapEntry struct {
+	ProperName  string
+	ProperEmail string
+	CommitName  string
+	CommitEmail string
+	Line        int
+}
+
+// String renders the entry back in mailmap syntax
+func (e MailmapEntry) String() string {
+	parts := make([]string, 0, 4)
+	if e.ProperName != "" {
+		parts = append(parts, e.ProperName)
+	}
+	if e.ProperEmail != "" {
+		parts = append(parts, "<"+e.ProperEmail+">")
+	}
+	if e.CommitName != "" {
+		parts = append(parts, e.CommitName)
+	}
+	parts = append(parts, "<"+e.CommitEmail+">")
+	return strings.Join(parts, " ")
+}
+
+// MailmapHit reports how often a mailmap entry rewrote an identity
+type MailmapHit struct {
+	Entry MailmapEntry
+	Count int
+}
+
+// Mailmap maps commit identities to canonical ones using the git .mailmap format.
+// It is safe for concurrent use and counts which entries fired.
+type Mailmap struct {
+	// byEmail holds entries keyed by lowercased commit email
+	byEmail map[string][]int
+	entries []MailmapEntry
+
+	mu   sync.Mutex
+	hits []int
+}
+
+// LoadMailmap reads a mailmap file from path
+func LoadMailmap(path string) (*Mailmap, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mailmap: %w", err)
+	}
+	defer file.Close()
+
+	mailmap, err := ParseMailmap(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse mailmap %s: %w", path, err)
+	}
+	return mailmap, nil
+}
+
+// ParseMailmap parses mailmap lines of the forms
+//
+//	Proper Name <commit@email>
+//	<proper@email> <commit@email>
+//	Proper Name <proper@email> <commit@email>
+//	Proper Name <proper@email> Commit Name <commit@email>
+func ParseMailmap(r io.Reader) (*Mailmap, error) {
+	mailmap := &Mailmap{byEmail: make(map[string][]int)}
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		entry, err := parseMailmapLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		entry.Line = lineNumber
+
+		key := strings.ToLower(entry.CommitEmail)
+		mailmap.byEmail[key] = append(mailmap.byEmail[key], len(mailmap.entries))
+		mailmap.entries = append(mailmap.entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	mailmap.hits = make([]int, len(mailmap.entries))
+	return mailmap, nil
+}
+
+func parseMailmapLine(line string) (MailmapEntry, error) {
+	names := make([]string, 0, 2)
+	emails := make([]string, 0, 2)
+
+	rest := line
+	for len(emails) < 2 {
+		open := strings.IndexByte(rest, '<')
+		if open < 0 {
+			break
+		}
+		closing := strings.IndexByte(rest[open:], '>')
+		if closing < 0 {
+			return MailmapEntry{}, fmt.Errorf("unterminated email in %q", line)
+		}
+		names = append(names, strings.TrimSpace(rest[:open]))
+		emails = append(emails, strings.TrimSpace(rest[open+1:open+closing]))
+		rest = rest[open+closing+1:]
+	}
+
+	// Anything after the last email is a trailing comment
+	if trailing := strings.TrimSpace(rest); trailing != "" && !strings.HasPrefix(trailing, "#") {
+		return MailmapEntry{}, fmt.Errorf("unexpected text after email in %q", line)
+	}
+
+	switch len(emails) {
+	case 1:
+		if names[0] == "" {
+			return MailmapEntry{}, fmt.Errorf("missing proper name in %q", line)
+		}
+		return MailmapEntry{ProperName: names[0], CommitEmail: emails[0]}, nil
+	case 2:
+		return MailmapEntry{
+			ProperName:  names[0],
+			ProperEmail: emails[0],
+			CommitName:  names[1],
+			CommitEmail: emails[1],
+		}, nil
+	default:
+		return MailmapEntry{}, fmt.Errorf("no email found in %q", line)
+	}
+}
+
+// Resolve maps a commit identity to its canonical name and email. Entries
+// that also match the commit name take precedence over email-only entries.
+func (m *Mailmap) Resolve(name, email string) (string, string) {
+	if m == nil {
+		return name, email
+	}
+
+	candidates := m.byEmail[strings.ToLower(email)]
+	match := -1
+	for _, index := range candidates {
+		entry := m.entries[index]
+		if entry.CommitName == "" {
+			if match < 0 {
+				match = index
+			}
+			continue
+		}
+		if strings.EqualFold(entry.CommitName, name) {
+			match = index
+			break
+		}
+	}
+	if match < 0 {
+		return name, email
+	}
+
+	m.mu.Lock()
+	m.hits[match]++
+	m.mu.Unlock()
+
+	entry := m.entries[match]
+	if entry.ProperName != "" {
+		name = entry.ProperName
+	}
+	if entry.ProperEmail != "" {
+		email = entry.ProperEmail
+	}
+	return name, email
+}
+
+// Hits returns the entries that rewrote at least one identity, in file order
+func (m *Mailmap) Hits() []MailmapHit {
+	if m == nil {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	hits := make([]MailmapHit, 0)
+	for index, count := range m.hits {
+		if count > 0 {
+			hits = append(hits, MailmapHit{Entry: m.entries[index], Count: count})
+		}
+	}
+	return hits
+}
+
+// Len returns the number of entries in the mailmap
+func (m *Mailmap) Len() int {
+	if m == nil {
+		return 0
+	}
+	return len(m.entries)
+}
+
+// GenerateMailmap builds entries that map every recorded identity of each
+// contributor to its display name and primary email. Identities without an
+// email cannot be expressed in mailmap syntax and are skipped, as are
+// identities that are already canonical.
+func GenerateMailmap(contributors []*types.ContributorStats) []MailmapEntry {
+	sorted := make([]*types.ContributorStats, len(contributors))
+	copy(sorted, contributors)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return strings.ToLower(sorted[i].Name) < strings.ToLower(sorted[j].Name)
+	})
+
+	entries := make([]MailmapEntry, 0)
+	for _, contributor := range sorted {
+		for _, identity := range contributor.Identities {
+			if identity.Email == "" {
+				continue
+			}
+			if identity.Name == contributor.Name && (identity.Email == contributor.Email || contributor.Email == "") {
+				continue
+			}
+
+			entry := MailmapEntry{
+				ProperName:  contributor.Name,
+				ProperEmail: contributor.Email,
+				CommitEmail: identity.Email,
+			}
+			if identity.Name != contributor.Name {
+				entry.CommitName = identity.Name
+				if entry.ProperEmail == "" {
+					entry.ProperEmail = identity.Email
+				}
+			}
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// RepositoryMailmap generates entries for the identities seen in repo, using
+// the canonical names and emails from the merged global statistics
+func RepositoryMailmap(repo *types.Repository, global *types.GlobalStats) []MailmapEntry {
+	contributors := make([]*types.ContributorStats, 0, len(repo.Contributors))
+	for key, stats := range repo.Contributors {
+		canonical := stats
+		if merged, ok := global.Contributors[key]; ok {
+			canonical = merged
+		}
+		contributors = append(contributors, &types.ContributorStats{
+			Name:       canonical.Name,
+			Email:      canonical.Email,
+			Identities: stats.Identities,
+		})
+	}
+	return GenerateMailmap(contributors)
+}
+
+// WriteMailmap writes entries in mailmap syntax, one per line
+func WriteMailmap(w io.Writer, entries []MailmapEntry) error {
+	for _, entry := range entries {
+		if _, err := fmt.Fprintln(w, entry.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MergeMailmapFile appends the entries not yet present in the mailmap at path,
+// creating the file if needed. Existing lines are never modified. It returns
+// the number of entries added.
+func MergeMailmapFile(path string, entries []MailmapEntry) (int, error) {
+	existing := &Mailmap{byEmail: make(map[string][]int)}
+	content, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if existing, err = ParseMailmap(strings.NewReader(string(content))); err != nil {
+			return 0, fmt.Errorf("failed to parse existing mailmap %s: %w", path, err)
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		return 0, fmt.Errorf("failed to read mailmap %s: %w", path, err)
+	}
+
+	missing := make([]MailmapEntry, 0, len(entries))
+	for _, entry := range entries {
+		if !existing.has(entry) {
+			missing = append(missing, entry)
+		}
+	}
+	if len(missing) == 0 {
+		return 0, nil
+	}
+
+	var builder strings.Builder
+	builder.Write(content)
+	if len(content) > 0 && !strings.HasSuffix(string(content), "\n") {
+		builder.WriteString("\n")
+	}
+	builder.WriteString("# Generated by ganalyzer\n")
+	if err := WriteMailmap(&builder, missing); err != nil {
+		return 0, err
+	}
+
+	if err := os.WriteFile(path, []byte(builder.String()), mailmapFilePermissions); err != nil {
+		return 0, fmt.Errorf("failed to write mailmap %s: %w", path, err)
+	}
+	return len(missing), nil
+}
+
+// has reports whether an entry for the same commit identity already exists
+func (m *Mailmap) has(entry MailmapEntry) bool {
+	for _, index := range m.byEmail[strings.ToLower(entry.CommitEmail)] {
+		if strings.EqualFold(m.entries[index].CommitName, entry.CommitName) {
+			return true
+		}
+	}
+	return false
+}
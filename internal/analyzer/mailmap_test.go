@@ -0,0 +1,173 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ganalyzer/pkg/types"
+)
+
+const testMailmap = `# Central identity mapping
+Jane Doe <jane@acme.com>
+<john@acme.com> <john@old-laptop.local>
+Martin Prazak <martin@acme.com> <mprazak@gmail.com>
+Bob Builder <bob@acme.com> bob <BUILD@ci.local>
+Unused Person <nobody@nowhere.org>
+`
+
+func TestParseMailmap(t *testing.T) {
+	mailmap, err := ParseMailmap(strings.NewReader(testMailmap))
+	if err != nil {
+		t.Fatalf("ParseMailmap failed: %v", err)
+	}
+
+	if mailmap.Len() != 5 {
+		t.Errorf("Expected 5 entries, got %d", mailmap.Len())
+	}
+
+	tests := []struct {
+		name          string
+		inputName     string
+		inputEmail    string
+		expectedName  string
+		expectedEmail string
+	}{
+		{"proper name only", "jdoe", "jane@acme.com", "Jane Doe", "jane@acme.com"},
+		{"proper email only", "John", "john@old-laptop.local", "John", "john@acme.com"},
+		{"name and email", "mp", "MPrazak@gmail.com", "Martin Prazak", "martin@acme.com"},
+		{"commit name must match", "Bob", "build@ci.local", "Bob Builder", "bob@acme.com"},
+		{"commit name mismatch", "release-bot", "build@ci.local", "release-bot", "build@ci.local"},
+		{"unknown email", "Alice", "alice@acme.com", "Alice", "alice@acme.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, email := mailmap.Resolve(tt.inputName, tt.inputEmail)
+			if name != tt.expectedName || email != tt.expectedEmail {
+				t.Errorf("Resolve(%q, %q) = %q, %q; want %q, %q",
+					tt.inputName, tt.inputEmail, name, email, tt.expectedName, tt.expectedEmail)
+			}
+		})
+	}
+
+	hits := mailmap.Hits()
+	if len(hits) != 4 {
+		t.Fatalf("Expected 4 entries to fire, got %d: %+v", len(hits), hits)
+	}
+	if hits[0].Entry.Line != 2 || hits[0].Count != 1 {
+		t.Errorf("Expected first hit on line 2, got %+v", hits[0])
+	}
+	if hits[3].Entry.String() != "Bob Builder <bob@acme.com> bob <BUILD@ci.local>" {
+		t.Errorf("Unexpected rendering of entry: %s", hits[3].Entry)
+	}
+}
+
+func TestParseMailmap_Invalid(t *testing.T) {
+	invalid := []string{
+		"Name without email",
+		"<only@email.com>",
+		"Broken <unterminated",
+		"Name <a@b.c> <d@e.f> extra",
+	}
+
+	for _, line := range invalid {
+		if _, err := ParseMailmap(strings.NewReader(line)); err == nil {
+			t.Errorf("Expected error for %q", line)
+		}
+	}
+}
+
+func TestMailmap_NilIsIdentity(t *testing.T) {
+	var mailmap *Mailmap
+	name, email := mailmap.Resolve("Name", "email@example.com")
+	if name != "Name" || email != "email@example.com" {
+		t.Errorf("Nil mailmap should not change identities, got %q %q", name, email)
+	}
+	if mailmap.Hits() != nil {
+		t.Error("Nil mailmap should have no hits")
+	}
+}
+
+func TestGenerateMailmap(t *testing.T) {
+	contributors := []*types.ContributorStats{
+		{
+			Name:  "Martin Pražák",
+			Email: "martin@acme.com",
+			Identities: []types.Identity{
+				{Name: "Martin Pražák", Email: "martin@acme.com"},
+				{Name: "martin.prazak", Email: "martin@acme.com"},
+				{Name: "Martin Prazak", Email: "mp@home.net"},
+				{Name: "Martin Pražák", Email: "martin@old.acme.com"},
+				{Name: "mp", Email: ""},
+			},
+		},
+		{
+			Name:       "Alice",
+			Email:      "alice@acme.com",
+			Identities: []types.Identity{{Name: "Alice", Email: "alice@acme.com"}},
+		},
+	}
+
+	var buf strings.Builder
+	if err := WriteMailmap(&buf, GenerateMailmap(contributors)); err != nil {
+		t.Fatalf("WriteMailmap failed: %v", err)
+	}
+
+	expected := "Martin Pražák <martin@acme.com> martin.prazak <martin@acme.com>\n" +
+		"Martin Pražák <martin@acme.com> Martin Prazak <mp@home.net>\n" +
+		"Martin Pražák <martin@acme.com> <martin@old.acme.com>\n"
+	if buf.String() != expected {
+		t.Errorf("Unexpected mailmap:\n%s\nwant:\n%s", buf.String(), expected)
+	}
+
+	// The generated file must map every alias back to the canonical identity
+	mailmap, err := ParseMailmap(strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatalf("Generated mailmap does not parse: %v", err)
+	}
+	for _, identity := range contributors[0].Identities[1:4] {
+		name, email := mailmap.Resolve(identity.Name, identity.Email)
+		if name != "Martin Pražák" || email != "martin@acme.com" {
+			t.Errorf("Resolve(%q, %q) = %q, %q", identity.Name, identity.Email, name, email)
+		}
+	}
+}
+
+func TestMergeMailmapFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".mailmap")
+	if err := os.WriteFile(path, []byte("Jane Doe <jane@acme.com>"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	entries := []MailmapEntry{
+		{ProperName: "Jane Doe", CommitEmail: "JANE@acme.com"},
+		{ProperName: "Bob", ProperEmail: "bob@acme.com", CommitEmail: "bob@laptop"},
+	}
+
+	added, err := MergeMailmapFile(path, entries)
+	if err != nil {
+		t.Fatalf("MergeMailmapFile failed: %v", err)
+	}
+	if added != 1 {
+		t.Errorf("Expected 1 new entry, got %d", added)
+	}
+
+	added, err = MergeMailmapFile(path, entries)
+	if err != nil {
+		t.Fatalf("MergeMailmapFile failed: %v", err)
+	}
+	if added != 0 {
+		t.Errorf("Expected merging twice to be a no-op, got %d new entries", added)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	expected := "Jane Doe <jane@acme.com>\n# Generated by ganalyzer\nBob <bob@acme.com> <bob@laptop>\n"
+	if string(content) != expected {
+		t.Errorf("Unexpected file content:\n%s", content)
+	}
+}
@@ -2,64 +2,79 @@ package analyzer
 
 import (
 	"regexp"
+	"sort"
 	"strings"
 )
 
 // NameNormalizer handles contributor name normalization
 type NameNormalizer struct {
-	punctuationRegex  *regexp.Regexp
-	diacriticReplacer *strings.Replacer
+	punctuationRegex *regexp.Regexp
 }
 
 // NewNameNormalizer creates a new name normalizer
 func NewNameNormalizer() *NameNormalizer {
-	// Common diacritic mappings - extend as needed
-	diacriticMappings := []string{
-		"á", "a", "à", "a", "â", "a", "ä", "a", "ā", "a", "ã", "a", "å", "a",
-		"é", "e", "è", "e", "ê", "e", "ë", "e", "ē", "e", "ę", "e", "ě", "e",
-		"í", "i", "ì", "i", "î", "i", "ï", "i", "ī", "i",
-		"ó", "o", "ò", "o", "ô", "o", "ö", "o", "ō", "o", "õ", "o",
-		"ú", "u", "ù", "u", "û", "u", "ü", "u", "ū", "u", "ů", "u",
-		"ý", "y", "ÿ", "y",
-		"ñ", "n",
-		"ç", "c", "č", "c",
-		"š", "s", "ž", "z", "ř", "r", "ď", "d", "ť", "t", "ň", "n",
-		// Add uppercase versions
-		"Á", "A", "À", "A", "Â", "A", "Ä", "A", "Ā", "A", "Ã", "A", "Å", "A",
-		"É", "E", "È", "E", "Ê", "E", "Ë", "E", "Ē", "E", "Ę", "E", "Ě", "E",
-		"Í", "I", "Ì", "I", "Î", "I", "Ï", "I", "Ī", "I",
-		"Ó", "O", "Ò", "O", "Ô", "O", "Ö", "O", "Ō", "O", "Õ", "O",
-		"Ú", "U", "Ù", "U", "Û", "U", "Ü", "U", "Ū", "U", "Ů", "U",
-		"Ý", "Y", "Ÿ", "Y",
-		"Ñ", "N",
-		"Ç", "C", "Č", "C",
-		"Š", "S", "Ž", "Z", "Ř", "R", "Ď", "D", "Ť", "T", "Ň", "N",
-	}
-
 	return &NameNormalizer{
-		punctuationRegex:  regexp.MustCompile(`[^\p{L}\p{N}]+`),
-		diacriticReplacer: strings.NewReplacer(diacriticMappings...),
+		punctuationRegex: regexp.MustCompile(`[^\p{L}\p{N}]+`),
 	}
 }
 
 // NormalizeName normalizes a contributor name by:
-// 1. Removing diacritics/accents using a replacer
-// 2. Converting to lowercase
+// 1. Moving the given names of a "Last, First" form in front of the surname
+// 2. Lowercasing, removing diacritics and transliterating letters (see foldName)
 // 3. Removing punctuation and whitespace to create a canonical form
 // This creates the most basic form (e.g., "michalpekny") that all variants map to
 func (nn *NameNormalizer) NormalizeName(name string) string {
+	return strings.Join(nn.tokens(name), "")
+}
+
+// TokenKey returns an order-insensitive signature of name: its normalized
+// words sorted and joined by spaces, so "Smith John" and "John Smith" match
+func (nn *NameNormalizer) TokenKey(name string) string {
+	tokens := nn.tokens(name)
+	sort.Strings(tokens)
+	return strings.Join(tokens, " ")
+}
+
+// tokens splits name into normalized lowercase words
+func (nn *NameNormalizer) tokens(name string) []string {
 	if name == "" {
-		return name
+		return nil
 	}
 
-	// Step 1: Remove diacritics
-	normalized := nn.diacriticReplacer.Replace(name)
+	normalized := foldName(reorderCommaName(name))
 
-	// Step 2: Convert to lowercase
-	normalized = strings.ToLower(normalized)
+	tokens := make([]string, 0)
+	for _, token := range nn.punctuationRegex.Split(normalized, -1) {
+		if token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
 
-	// Step 3: Remove all punctuation and whitespace to create canonical form
-	normalized = nn.punctuationRegex.ReplaceAllString(normalized, "")
+// reorderCommaName turns "Smith, John" into "John Smith". Names whose part
+// after the comma is a generational or academic suffix ("John Smith, Jr.")
+// are returned unchanged, as are names with more than one comma.
+func reorderCommaName(name string) string {
+	if strings.Count(name, ",") != 1 {
+		return name
+	}
+
+	parts := strings.SplitN(name, ",", 2)
+	last := strings.TrimSpace(parts[0])
+	first := strings.TrimSpace(parts[1])
+	if last == "" || first == "" || isNameSuffix(first) {
+		return name
+	}
+
+	return first + " " + last
+}
 
-	return normalized
+func isNameSuffix(part string) bool {
+	switch strings.ToLower(strings.Trim(part, ". ")) {
+	case "jr", "sr", "ii", "iii", "iv", "phd", "ph.d", "md", "esq":
+		return true
+	default:
+		return false
+	}
 }
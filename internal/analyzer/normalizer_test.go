@@ -144,3 +144,81 @@ func TestNameNormalizer_MichalPeknyVariants(t *testing.T) {
 		}
 	}
 }
+
+func TestNameNormalizer_NameOrder(t *testing.T) {
+	normalizer := NewNameNormalizer()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "last comma first",
+			input:    "Smith, John",
+			expected: "johnsmith",
+		},
+		{
+			name:     "last comma first with middle initial",
+			input:    "Smith, John A.",
+			expected: "johnasmith",
+		},
+		{
+			name:     "comma without space",
+			input:    "Pražák,Martin",
+			expected: "martinprazak",
+		},
+		{
+			name:     "generational suffix is not reordered",
+			input:    "John Smith, Jr.",
+			expected: "johnsmithjr",
+		},
+		{
+			name:     "multiple commas are not reordered",
+			input:    "Smith, John, Jr",
+			expected: "smithjohnjr",
+		},
+		{
+			name:     "trailing comma",
+			input:    "Smith,",
+			expected: "smith",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := normalizer.NormalizeName(tt.input)
+			if result != tt.expected {
+				t.Errorf("NormalizeName(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+
+	if normalizer.NormalizeName("Smith, John") != normalizer.NormalizeName("John Smith") {
+		t.Error("Expected \"Smith, John\" and \"John Smith\" to normalize identically")
+	}
+}
+
+func TestNameNormalizer_TokenKey(t *testing.T) {
+	normalizer := NewNameNormalizer()
+
+	variations := []string{
+		"John Smith",
+		"Smith John",
+		"Smith, John",
+		"smith.john",
+		"JOHN  SMITH",
+	}
+
+	expected := "john smith"
+	for _, variation := range variations {
+		result := normalizer.TokenKey(variation)
+		if result != expected {
+			t.Errorf("TokenKey(%q) = %q, want %q", variation, result, expected)
+		}
+	}
+
+	if result := normalizer.TokenKey("johnsmith"); result != "johnsmith" {
+		t.Errorf("TokenKey(\"johnsmith\") = %q, want single token", result)
+	}
+}
@@ -0,0 +1,222 @@
+package analyzer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"ganalyzer/pkg/types"
+)
+
+// blameOwner is an author and the number of lines at HEAD they last changed
+type blameOwner struct {
+	name  string
+	email string
+	lines int
+}
+
+// blameOwnership attributes every line of the text files at HEAD that pass
+// the path filter to the contributor who last changed it, as `git blame` sees it
+func (a *Analyzer) blameOwnership(repo *types.Repository, filter *repositoryFilter) error {
+	files, err := textFiles(repo.Path, repo.Head)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if filter.excludedBy(file) != "" {
+			continue
+		}
+		owners, err := blameFile(repo.Path, repo.Head, file)
+		if err != nil {
+			// One file git cannot blame should not cost the whole repository
+			continue
+		}
+		for _, owner := range owners {
+			if owner.name == "" {
+				continue
+			}
+			// git has already applied the repository's .mailmap
+			name, email := a.mailmap.Resolve(owner.name, owner.email)
+			key := a.registerContributor(repo, name, email)
+			repo.Contributors[key].OwnedLines += owner.lines
+			repo.SurvivingLines += owner.lines
+			if a.concentration {
+				repo.AddFileWeight(file, key, owner.lines)
+			}
+		}
+	}
+	return nil
+}
+
+// textFiles lists the non-empty regular text files in the tree of commit.
+// Diffing against the empty tree makes git report binary files with "-" line
+// counts; submodules and symlinks, which it lists too, are left out by their
+// tree entries.
+func textFiles(repoPath, commit string) ([]string, error) {
+	blobs, err := regularFiles(repoPath, commit)
+	if err != nil {
+		return nil, err
+	}
+
+	hashCmd := exec.Command("git", "-C", repoPath, "hash-object", "-t", "tree", "--stdin")
+	hashCmd.Stdin = strings.NewReader("")
+	emptyTree, err := hashCmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git hash-object failed: %w", err)
+	}
+
+	output, err := exec.Command("git", "-C", repoPath, "diff-tree", "-r", "-z", "--numstat", "--no-renames",
+		strings.TrimSpace(string(emptyTree)), commit).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git diff-tree failed: %w", err)
+	}
+
+	files := make([]string, 0)
+	for _, entry := range strings.Split(string(output), "\x00") {
+		parts := strings.SplitN(entry, "\t", numstatColumns)
+		if len(parts) != numstatColumns {
+			continue
+		}
+		if added, err := strconv.Atoi(parts[0]); err == nil && added > 0 && blobs[parts[2]] {
+			files = append(files, parts[2])
+		}
+	}
+	return files, nil
+}
+
+// regularFiles returns the paths of the regular and executable files in the
+// tree of commit, leaving out submodule gitlinks and symlinks
+func regularFiles(repoPath, commit string) (map[string]bool, error) {
+	output, err := exec.Command("git", "-C", repoPath, "ls-tree", "-r", "-z", commit).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git ls-tree failed: %w", err)
+	}
+
+	files := make(map[string]bool)
+	for _, entry := range strings.Split(string(output), "\x00") {
+		// "<mode> <type> <object>\t<path>"
+		info, path, ok := strings.Cut(entry, "\t")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(info)
+		if len(fields) == 3 && fields[1] == "blob" && (fields[0] == "100644" || fields[0] == "100755") {
+			files[path] = true
+		}
+	}
+	return files, nil
+}
+
+// blameFile returns the authors of the lines of path at commit, in order of
+// first appearance
+func blameFile(repoPath, commit, path string) ([]blameOwner, error) {
+	output, err := exec.Command("git", "-C", repoPath, "blame", "--porcelain", commit, "--", path).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git blame %s failed: %w", path, err)
+	}
+	return parseBlame(output)
+}
+
+// parseBlame reads `git blame --porcelain` output. Every line of the file is
+// a "<commit> <original line> <final line> [<group size>]" header followed by
+// the content prefixed with a tab; the author fields follow the header only
+// the first time a commit appears.
+func parseBlame(output []byte) ([]blameOwner, error) {
+	owners := make([]blameOwner, 0)
+	index := make(map[string]int)
+	current := ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "\t"):
+			if i, ok := index[current]; ok {
+				owners[i].lines++
+			}
+		case strings.HasPrefix(line, "author "):
+			if i, ok := index[current]; ok {
+				owners[i].name = strings.TrimPrefix(line, "author ")
+			}
+		case strings.HasPrefix(line, "author-mail "):
+			if i, ok := index[current]; ok {
+				email := strings.TrimPrefix(line, "author-mail ")
+				owners[i].email = strings.TrimSuffix(strings.TrimPrefix(email, "<"), ">")
+			}
+		default:
+			fields := strings.Fields(line)
+			if len(fields) >= 3 && isCommitHash(fields[0]) {
+				current = fields[0]
+				if _, ok := index[current]; !ok {
+					index[current] = len(owners)
+					owners = append(owners, blameOwner{})
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read git blame output: %w", err)
+	}
+	return mergeOwners(owners), nil
+}
+
+// mergeOwners combines the entries of authors owning lines through several commits
+func mergeOwners(owners []blameOwner) []blameOwner {
+	merged := make([]blameOwner, 0, len(owners))
+	index := make(map[blameOwner]int)
+	for _, owner := range owners {
+		identity := blameOwner{name: owner.name, email: owner.email}
+		if i, ok := index[identity]; ok {
+			merged[i].lines += owner.lines
+			continue
+		}
+		index[identity] = len(merged)
+		merged = append(merged, owner)
+	}
+	return merged
+}
+
+// isCommitHash reports whether s looks like a full SHA-1 or SHA-256 object name
+func isCommitHash(s string) bool {
+	if len(s) != 40 && len(s) != 64 {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// keepFilesAt drops the file weights of files that no longer exist at commit,
+// such as deleted or renamed ones
+func keepFilesAt(repo *types.Repository, commit string) error {
+	if len(repo.Files) == 0 {
+		return nil
+	}
+	if commit == "" {
+		repo.Files = nil
+		return nil
+	}
+
+	output, err := exec.Command("git", "-C", repo.Path, "ls-tree", "-r", "-z", "--name-only", commit).Output()
+	if err != nil {
+		return fmt.Errorf("git ls-tree failed: %w", err)
+	}
+	exists := make(map[string]bool)
+	for _, path := range strings.Split(string(output), "\x00") {
+		exists[path] = true
+	}
+	for path := range repo.Files {
+		if !exists[path] {
+			delete(repo.Files, path)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseBlame(t *testing.T) {
+	alice := "1111111111111111111111111111111111111111"
+	bob := "2222222222222222222222222222222222222222"
+	aliceAgain := "3333333333333333333333333333333333333333"
+
+	output := alice + " 1 1 2\n" +
+		"author Alice\nauthor-mail <alice@example.com>\nsummary Add file\nfilename main.go\n" +
+		"\tpackage main\n" +
+		alice + " 2 2\n" +
+		"\t\n" +
+		bob + " 3 3 1\n" +
+		"author Bob\nauthor-mail <bob@example.com>\nsummary Fix\nfilename main.go\n" +
+		"\tfunc main() {}\n" +
+		aliceAgain + " 4 4 1\n" +
+		"author Alice\nauthor-mail <alice@example.com>\nsummary Add comment\nfilename main.go\n" +
+		"\t// author Mallory\n"
+
+	owners, err := parseBlame([]byte(output))
+	if err != nil {
+		t.Fatalf("parseBlame failed: %v", err)
+	}
+	if len(owners) != 2 {
+		t.Fatalf("Expected 2 owners, got %+v", owners)
+	}
+	if owners[0] != (blameOwner{name: "Alice", email: "alice@example.com", lines: 3}) {
+		t.Errorf("Unexpected first owner: %+v", owners[0])
+	}
+	if owners[1] != (blameOwner{name: "Bob", email: "bob@example.com", lines: 1}) {
+		t.Errorf("Unexpected second owner: %+v", owners[1])
+	}
+}
+
+func TestParseBlame_LongLine(t *testing.T) {
+	output := "1111111111111111111111111111111111111111 1 1 1\n" +
+		"author Alice\nauthor-mail <alice@example.com>\n" +
+		"\t" + strings.Repeat("x", 17*1024*1024) + "\n"
+	if _, err := parseBlame([]byte(output)); err == nil {
+		t.Error("Expected an error for a line longer than the scan buffer")
+	}
+}
+
+func TestAnalyzer_Ownership(t *testing.T) {
+	if !hasGit() {
+		t.Skip("Git not available, skipping integration test")
+	}
+
+	tempDir := createTestGitRepo(t)
+	commitAs(t, tempDir, "Alice", "alice@example.com", "notes.txt", "one\ntwo\nthree\n")
+	commitAs(t, tempDir, "Bob", "bob@example.com", "notes.txt", "one\n2\nthree\n")
+	commitAs(t, tempDir, "Bob", "bob@example.com", "logo.png", "\x89PNG\x00\x00\x01")
+
+	repo, err := NewAnalyzerWithOptions(Options{Ownership: true}).AnalyzeRepository(tempDir)
+	if err != nil {
+		t.Fatalf("AnalyzeRepository failed: %v", err)
+	}
+
+	owned := map[string]int{}
+	for key, stats := range repo.Contributors {
+		owned[key] = stats.OwnedLines
+	}
+	// test.txt and test2.txt have two lines each
+	want := map[string]int{"Test User": 4, "Alice": 2, "Bob": 1}
+	for key, lines := range want {
+		if owned[key] != lines {
+			t.Errorf("Expected %s to own %d lines, got %d", key, lines, owned[key])
+		}
+	}
+	if repo.SurvivingLines != 7 {
+		t.Errorf("Expected 7 surviving lines, got %d", repo.SurvivingLines)
+	}
+}
+
+func TestAnalyzer_OwnershipSkipsSubmodules(t *testing.T) {
+	if !hasGit() {
+		t.Skip("Git not available, skipping integration test")
+	}
+
+	library := createTestGitRepo(t)
+	tempDir := createTestGitRepo(t)
+	if err := runCmd(tempDir, "git", "-c", "protocol.file.allow=always", "submodule", "add", "-q", library, "lib"); err != nil {
+		t.Fatalf("git submodule add failed: %v", err)
+	}
+	if err := runCmd(tempDir, "git", "commit", "-q", "--author", "Alice <alice@example.com>", "-m", "Add lib"); err != nil {
+		t.Fatalf("git commit failed: %v", err)
+	}
+
+	repo, err := NewAnalyzerWithOptions(Options{Ownership: true}).AnalyzeRepository(tempDir)
+	if err != nil {
+		t.Fatalf("AnalyzeRepository failed: %v", err)
+	}
+	// test.txt and test2.txt, plus the three lines of .gitmodules
+	if repo.SurvivingLines != 7 || repo.Contributors["Alice"].OwnedLines != 3 {
+		t.Errorf("Expected the submodule to be skipped, got %d surviving lines and %+v", repo.SurvivingLines, repo.Contributors["Alice"])
+	}
+
+	files, err := textFiles(tempDir, "HEAD")
+	if err != nil {
+		t.Fatalf("textFiles failed: %v", err)
+	}
+	for _, file := range files {
+		if file == "lib" {
+			t.Errorf("Expected the gitlink to be left out, got %v", files)
+		}
+	}
+}
+
+func TestAnalyzer_ConcentrationFiles(t *testing.T) {
+	if !hasGit() {
+		t.Skip("Git not available, skipping integration test")
+	}
+
+	tempDir := createTestGitRepo(t)
+	commitAs(t, tempDir, "Alice", "alice@example.com", "gone.txt", "temporary\n")
+	if err := runCmd(tempDir, "git", "rm", "-q", "gone.txt"); err != nil {
+		t.Fatalf("git rm failed: %v", err)
+	}
+	if err := runCmd(tempDir, "git", "commit", "-m", "Remove gone.txt"); err != nil {
+		t.Fatalf("git commit failed: %v", err)
+	}
+	commitAs(t, tempDir, "Alice", "alice@example.com", "test.txt", "Hello, Alice!\nSecond line\n")
+
+	for _, ownership := range []bool{false, true} {
+		repo, err := NewAnalyzerWithOptions(Options{Concentration: true, Ownership: ownership}).AnalyzeRepository(tempDir)
+		if err != nil {
+			t.Fatalf("AnalyzeRepository failed: %v", err)
+		}
+
+		if _, ok := repo.Files["gone.txt"]; ok || len(repo.Files) != 2 {
+			t.Errorf("ownership=%v: expected only the files at HEAD, got %v", ownership, repo.Files)
+		}
+		if weights := repo.Files["test.txt"]; weights["Alice"] == 0 || weights["Test User"] == 0 {
+			t.Errorf("ownership=%v: expected both authors of test.txt, got %v", ownership, weights)
+		}
+	}
+}
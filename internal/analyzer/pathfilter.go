@@ -0,0 +1,299 @@
+package analyzer
+
+import (
+	"bufio"
+	"fmt"
+	"os/exec"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// Names under which lines removed by .gitattributes are reported
+	attributeGenerated = "linguist-generated"
+	attributeVendored  = "linguist-vendored"
+
+	// notIncluded reports lines outside every include pattern
+	notIncluded = "not included"
+)
+
+// DefaultExcludes are the paths left out of line statistics unless disabled:
+// lockfiles, vendored dependencies, generated protobuf code and minified assets
+var DefaultExcludes = []string{
+	"package-lock.json", "npm-shrinkwrap.json", "yarn.lock", "pnpm-lock.yaml", "bun.lockb",
+	"Gemfile.lock", "Cargo.lock", "composer.lock", "poetry.lock", "Pipfile.lock",
+	"go.sum", "mix.lock", "Podfile.lock", "packages.lock.json", "pubspec.lock",
+	"vendor/", "node_modules/", "bower_components/",
+	"*.pb.go", "*.pb.gw.go", "*.pb.cc", "*.pb.h", "*_pb2.py", "*_pb2_grpc.py", "*_pb.js", "*_pb.d.ts",
+	"*.min.js", "*.min.css", "*.min.map",
+}
+
+// pathPattern is a compiled glob
+type pathPattern struct {
+	glob   string
+	regexp *regexp.Regexp
+}
+
+// compileGlob turns a glob into a pattern matched against paths relative to
+// the repository root, following .gitignore conventions: a glob without a
+// slash matches a file name at any depth, a trailing slash matches a
+// directory at any depth, a leading slash anchors the glob at the root, and
+// "**" spans directories.
+func compileGlob(glob string) (pathPattern, error) {
+	pattern := strings.TrimSpace(glob)
+	if pattern == "" || pattern == "/" {
+		return pathPattern{}, fmt.Errorf("empty path pattern")
+	}
+
+	directory := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	var expr strings.Builder
+	if !anchored {
+		expr.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '*' && strings.HasPrefix(pattern[i:], "**/"):
+			expr.WriteString("(?:.*/)?")
+			i += 2
+		case c == '*' && strings.HasPrefix(pattern[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case c == '*':
+			expr.WriteString("[^/]*")
+		case c == '?':
+			expr.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return pathPattern{}, fmt.Errorf("unterminated character class in pattern %q", glob)
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	if directory {
+		expr.WriteString("/.*")
+	} else {
+		// A pattern naming a directory also covers everything below it
+		expr.WriteString("(?:/.*)?")
+	}
+
+	re, err := regexp.Compile("^" + expr.String() + "$")
+	if err != nil {
+		return pathPattern{}, fmt.Errorf("invalid path pattern %q: %w", glob, err)
+	}
+	return pathPattern{glob: glob, regexp: re}, nil
+}
+
+func (p pathPattern) match(filePath string) bool {
+	return p.regexp.MatchString(filePath)
+}
+
+// PathFilter selects the files whose lines count towards line statistics
+type PathFilter struct {
+	include []pathPattern
+	exclude []pathPattern
+	// attributes enables the linguist-generated and linguist-vendored
+	// attributes of each repository's .gitattributes
+	attributes bool
+}
+
+// NewPathFilter compiles include and exclude globs. With includes, only
+// matching paths are counted; excludes are applied on top, after
+// DefaultExcludes when defaults is set. Empty globs are ignored.
+func NewPathFilter(include, exclude []string, defaults, attributes bool) (*PathFilter, error) {
+	filter := &PathFilter{attributes: attributes}
+	for _, glob := range include {
+		if strings.TrimSpace(glob) == "" {
+			continue
+		}
+		pattern, err := compileGlob(glob)
+		if err != nil {
+			return nil, err
+		}
+		filter.include = append(filter.include, pattern)
+	}
+
+	if defaults {
+		exclude = append(append([]string(nil), DefaultExcludes...), exclude...)
+	}
+	for _, glob := range exclude {
+		if strings.TrimSpace(glob) == "" {
+			continue
+		}
+		pattern, err := compileGlob(glob)
+		if err != nil {
+			return nil, err
+		}
+		filter.exclude = append(filter.exclude, pattern)
+	}
+	return filter, nil
+}
+
+// attributeRule is one pattern line of a .gitattributes file, scoped to the
+// directory holding the file
+type attributeRule struct {
+	dir     string
+	pattern pathPattern
+	// values maps attribute names to whether the line sets or unsets them
+	values map[string]bool
+}
+
+// repositoryFilter is a PathFilter together with one repository's
+// .gitattributes rules
+type repositoryFilter struct {
+	*PathFilter
+	rules []attributeRule
+}
+
+// forRepository loads the .gitattributes files at commit when attributes are
+// enabled. Unreadable attributes are ignored, as git ignores broken lines.
+func (f *PathFilter) forRepository(repoPath, commit string) *repositoryFilter {
+	if f == nil {
+		return nil
+	}
+	filter := &repositoryFilter{PathFilter: f}
+	if f.attributes && commit != "" {
+		filter.rules = loadAttributeRules(repoPath, commit)
+	}
+	return filter
+}
+
+// excludedBy returns the name of the filter removing filePath from line
+// statistics, or "" when its lines count
+func (f *repositoryFilter) excludedBy(filePath string) string {
+	if f == nil {
+		return ""
+	}
+	if len(f.include) > 0 {
+		included := false
+		for _, pattern := range f.include {
+			if pattern.match(filePath) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return notIncluded
+		}
+	}
+	for _, pattern := range f.exclude {
+		if pattern.match(filePath) {
+			return pattern.glob
+		}
+	}
+	for _, attribute := range []string{attributeGenerated, attributeVendored} {
+		if f.attribute(filePath, attribute) {
+			return attribute
+		}
+	}
+	return ""
+}
+
+// attribute reports whether the last rule mentioning attribute that matches
+// filePath sets it; rules of deeper .gitattributes files come later
+func (f *repositoryFilter) attribute(filePath, attribute string) bool {
+	set := false
+	for _, rule := range f.rules {
+		value, ok := rule.values[attribute]
+		if !ok {
+			continue
+		}
+		relative := filePath
+		if rule.dir != "" {
+			if !strings.HasPrefix(filePath, rule.dir+"/") {
+				continue
+			}
+			relative = strings.TrimPrefix(filePath, rule.dir+"/")
+		}
+		if rule.pattern.match(relative) {
+			set = value
+		}
+	}
+	return set
+}
+
+// loadAttributeRules reads every .gitattributes file in the tree of commit,
+// shallowest first
+func loadAttributeRules(repoPath, commit string) []attributeRule {
+	output, err := exec.Command("git", "-C", repoPath, "ls-tree", "-r", "-z", "--name-only", commit).Output()
+	if err != nil {
+		return nil
+	}
+
+	files := make([]string, 0)
+	for _, name := range strings.Split(string(output), "\x00") {
+		if path.Base(name) == ".gitattributes" {
+			files = append(files, name)
+		}
+	}
+	sort.SliceStable(files, func(i, j int) bool {
+		return strings.Count(files[i], "/") < strings.Count(files[j], "/")
+	})
+
+	rules := make([]attributeRule, 0)
+	for _, name := range files {
+		content, err := exec.Command("git", "-C", repoPath, "show", commit+":"+name).Output()
+		if err != nil {
+			continue
+		}
+		dir := path.Dir(name)
+		if dir == "." {
+			dir = ""
+		}
+		rules = append(rules, parseAttributes(string(content), dir)...)
+	}
+	return rules
+}
+
+// parseAttributes reads the linguist-generated and linguist-vendored settings
+// of a .gitattributes file: "attr" and "attr=true" set an attribute, "-attr",
+// "!attr" and "attr=false" unset it
+func parseAttributes(content, dir string) []attributeRule {
+	rules := make([]attributeRule, 0)
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		values := make(map[string]bool)
+		for _, field := range fields[1:] {
+			name, value, hasValue := strings.Cut(field, "=")
+			set := true
+			switch {
+			case strings.HasPrefix(name, "-") || strings.HasPrefix(name, "!"):
+				name, set = name[1:], false
+			case hasValue:
+				set = value != "false"
+			}
+			if name == attributeGenerated || name == attributeVendored {
+				values[name] = set
+			}
+		}
+		if len(values) == 0 {
+			continue
+		}
+
+		pattern, err := compileGlob(fields[0])
+		if err != nil {
+			continue
+		}
+		rules = append(rules, attributeRule{dir: dir, pattern: pattern, values: values})
+	}
+	return rules
+}
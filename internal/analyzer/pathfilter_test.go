@@ -0,0 +1,182 @@
+package analyzer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompileGlob(t *testing.T) {
+	tests := []struct {
+		glob  string
+		path  string
+		match bool
+	}{
+		{"package-lock.json", "package-lock.json", true},
+		{"package-lock.json", "web/package-lock.json", true},
+		{"package-lock.json", "package-lock.json.bak", false},
+		{"*.min.js", "static/js/app.min.js", true},
+		{"*.min.js", "static/js/app.js", false},
+		{"vendor/", "vendor/github.com/pkg/errors/errors.go", true},
+		{"vendor/", "src/vendor/lib.c", true},
+		{"vendor/", "vendor", false},
+		{"vendor/", "vendored.go", false},
+		{"docs", "docs/index.md", true},
+		{"/docs", "docs/index.md", true},
+		{"/docs", "api/docs/index.md", false},
+		{"api/*.go", "api/server.go", true},
+		{"api/*.go", "api/v1/server.go", false},
+		{"api/*.go", "internal/api/server.go", false},
+		{"api/**/*.go", "api/v1/server.go", true},
+		{"api/**/*.go", "api/server.go", true},
+		{"**/testdata/**", "internal/analyzer/testdata/log.txt", true},
+		{"file?.txt", "file1.txt", true},
+		{"file?.txt", "file10.txt", false},
+		{"[ab].go", "a.go", true},
+		{"[!ab].go", "a.go", false},
+		{"[!ab].go", "c.go", true},
+	}
+
+	for _, tt := range tests {
+		pattern, err := compileGlob(tt.glob)
+		if err != nil {
+			t.Fatalf("compileGlob(%q) failed: %v", tt.glob, err)
+		}
+		if got := pattern.match(tt.path); got != tt.match {
+			t.Errorf("compileGlob(%q).match(%q) = %v, want %v", tt.glob, tt.path, got, tt.match)
+		}
+	}
+}
+
+func TestCompileGlob_Invalid(t *testing.T) {
+	for _, glob := range []string{"", "/", "file[.go"} {
+		if _, err := compileGlob(glob); err == nil {
+			t.Errorf("compileGlob(%q) succeeded, want an error", glob)
+		}
+	}
+}
+
+func TestPathFilter_ExcludedBy(t *testing.T) {
+	filter, err := NewPathFilter([]string{"src/", "go.sum"}, []string{"*_test.go", ""}, true, true)
+	if err != nil {
+		t.Fatalf("NewPathFilter failed: %v", err)
+	}
+	repoFilter := &repositoryFilter{
+		PathFilter: filter,
+		rules:      parseAttributes("src/gen/** linguist-generated\nsrc/gen/keep.go -linguist-generated\n", ""),
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"src/main.go", ""},
+		{"src/main_test.go", "*_test.go"},
+		{"README.md", notIncluded},
+		{"go.sum", "go.sum"},
+		{"src/vendor/lib.go", "vendor/"},
+		{"src/gen/types.go", attributeGenerated},
+		{"src/gen/keep.go", ""},
+	}
+	for _, tt := range tests {
+		if got := repoFilter.excludedBy(tt.path); got != tt.want {
+			t.Errorf("excludedBy(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	var nilFilter *PathFilter
+	if got := nilFilter.forRepository(".", "HEAD").excludedBy("package-lock.json"); got != "" {
+		t.Errorf("Expected a nil filter to keep every path, got %q", got)
+	}
+}
+
+func TestParseAttributes(t *testing.T) {
+	content := "# Generated code\n" +
+		"*.gen.go linguist-generated=true\n" +
+		"third_party/** linguist-vendored text eol=lf\n" +
+		"third_party/ours/** !linguist-vendored\n" +
+		"*.txt text\n" +
+		"docs/*.md linguist-documentation linguist-generated=false\n"
+
+	rules := parseAttributes(content, "web")
+	if len(rules) != 4 {
+		t.Fatalf("Expected 4 rules, got %+v", rules)
+	}
+
+	want := []map[string]bool{
+		{attributeGenerated: true},
+		{attributeVendored: true},
+		{attributeVendored: false},
+		{attributeGenerated: false},
+	}
+	for i, rule := range rules {
+		if rule.dir != "web" {
+			t.Errorf("rule %d: expected dir web, got %q", i, rule.dir)
+		}
+		if !reflect.DeepEqual(rule.values, want[i]) {
+			t.Errorf("rule %d: expected %v, got %v", i, want[i], rule.values)
+		}
+	}
+
+	filter := &repositoryFilter{PathFilter: &PathFilter{attributes: true}, rules: rules}
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"web/api.gen.go", attributeGenerated},
+		{"api.gen.go", ""},
+		{"web/third_party/lib/lib.js", attributeVendored},
+		{"web/third_party/ours/lib.js", ""},
+	}
+	for _, tt := range tests {
+		if got := filter.excludedBy(tt.path); got != tt.want {
+			t.Errorf("excludedBy(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyzer_PathFilters(t *testing.T) {
+	if !hasGit() {
+		t.Skip("Git not available, skipping integration test")
+	}
+
+	tempDir := createTestGitRepo(t)
+	commitAs(t, tempDir, "Alice", "alice@example.com", "package-lock.json", "{\n}\n")
+	commitAs(t, tempDir, "Alice", "alice@example.com", ".gitattributes", "*.gen.go linguist-generated\n")
+	commitAs(t, tempDir, "Bob", "bob@example.com", "api.gen.go", "package api\n\nvar x = 1\n")
+	commitAs(t, tempDir, "Bob", "bob@example.com", "api.go", "package api\n")
+
+	filter, err := NewPathFilter(nil, []string{"test2.txt"}, true, true)
+	if err != nil {
+		t.Fatalf("NewPathFilter failed: %v", err)
+	}
+	repo, err := NewAnalyzerWithOptions(Options{Paths: filter, Ownership: true}).AnalyzeRepository(tempDir)
+	if err != nil {
+		t.Fatalf("AnalyzeRepository failed: %v", err)
+	}
+
+	wantFiltered := map[string]int{"package-lock.json": 2, attributeGenerated: 3, "test2.txt": 2}
+	if !reflect.DeepEqual(repo.FilteredLines, wantFiltered) {
+		t.Errorf("Expected filtered lines %v, got %v", wantFiltered, repo.FilteredLines)
+	}
+
+	tests := []struct {
+		key     string
+		commits int
+		added   int
+		owned   int
+	}{
+		{"Test User", 2, 2, 2},
+		{"Alice", 2, 1, 1},
+		{"Bob", 2, 1, 1},
+	}
+	for _, tt := range tests {
+		stats := repo.Contributors[tt.key]
+		if stats == nil {
+			t.Fatalf("Missing contributor %s", tt.key)
+		}
+		if stats.CommitCount != tt.commits || stats.LinesAdded != tt.added || stats.OwnedLines != tt.owned {
+			t.Errorf("%s: expected %d commits, %d lines added and %d owned, got %d, %d and %d",
+				tt.key, tt.commits, tt.added, tt.owned, stats.CommitCount, stats.LinesAdded, stats.OwnedLines)
+		}
+	}
+}
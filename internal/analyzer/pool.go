@@ -0,0 +1,73 @@
+package analyzer
+
+import (
+	"sync"
+
+	"ganalyzer/pkg/types"
+)
+
+// Result holds the outcome of analyzing a single repository
+type Result struct {
+	Path       string
+	Repository *types.Repository
+	Err        error
+}
+
+// ProgressFunc is called once per finished repository. Calls are serialized,
+// and done counts completed repositories out of total.
+type ProgressFunc func(done, total int, result Result)
+
+// StreamRepositories analyzes repoPaths with at most jobs concurrent workers
+// and hands each result to consume as soon as it and all repositories before
+// it have finished. Results therefore arrive in the order of repoPaths
+// regardless of which repository finishes first, so callers can merge them
+// deterministically without holding every repository's commit ledger until
+// the last one is done. Calls to consume are serialized with those to progress.
+func (a *Analyzer) StreamRepositories(repoPaths []string, jobs int, progress ProgressFunc, consume func(Result)) {
+	if len(repoPaths) == 0 {
+		return
+	}
+
+	if jobs < 1 {
+		jobs = 1
+	}
+	if jobs > len(repoPaths) {
+		jobs = len(repoPaths)
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	finished := make([]*Result, len(repoPaths))
+	done, next := 0, 0
+
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				repo, err := a.AnalyzeRepository(repoPaths[i])
+				result := Result{Path: repoPaths[i], Repository: repo, Err: err}
+
+				mu.Lock()
+				done++
+				if progress != nil {
+					progress(done, len(repoPaths), result)
+				}
+				finished[i] = &result
+				for next < len(repoPaths) && finished[next] != nil {
+					consume(*finished[next])
+					finished[next] = nil
+					next++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for i := range repoPaths {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+}
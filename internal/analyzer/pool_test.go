@@ -0,0 +1,71 @@
+package analyzer
+
+import (
+	"testing"
+)
+
+func TestAnalyzer_StreamRepositories(t *testing.T) {
+	if !hasGit() {
+		t.Skip("Git not available, skipping integration test")
+	}
+
+	paths := []string{
+		createTestGitRepo(t),
+		"/nonexistent/repo",
+		createTestGitRepo(t),
+		createTestGitRepo(t),
+	}
+
+	lastDone := 0
+	var results []Result
+	NewAnalyzer().StreamRepositories(paths, 3, func(done, total int, _ Result) {
+		if done != lastDone+1 {
+			t.Errorf("Expected progress %d, got %d", lastDone+1, done)
+		}
+		lastDone = done
+		if total != len(paths) {
+			t.Errorf("Expected total %d, got %d", len(paths), total)
+		}
+	}, func(result Result) {
+		if len(results) >= lastDone {
+			t.Errorf("Result %s consumed before it finished", result.Path)
+		}
+		results = append(results, result)
+	})
+
+	if lastDone != len(paths) {
+		t.Errorf("Expected %d progress calls, got %d", len(paths), lastDone)
+	}
+
+	if len(results) != len(paths) {
+		t.Fatalf("Expected %d results, got %d", len(paths), len(results))
+	}
+
+	for i, result := range results {
+		if result.Path != paths[i] {
+			t.Errorf("Result %d: expected path %s, got %s", i, paths[i], result.Path)
+		}
+	}
+
+	if results[1].Err == nil {
+		t.Error("Expected error for nonexistent repository")
+	}
+
+	for _, i := range []int{0, 2, 3} {
+		if results[i].Err != nil {
+			t.Errorf("Unexpected error for %s: %v", paths[i], results[i].Err)
+			continue
+		}
+		if results[i].Repository.Path != paths[i] {
+			t.Errorf("Result %d holds repository %s, want %s", i, results[i].Repository.Path, paths[i])
+		}
+	}
+}
+
+func TestAnalyzer_StreamRepositoriesEmpty(t *testing.T) {
+	consumed := 0
+	NewAnalyzer().StreamRepositories(nil, 4, nil, func(Result) { consumed++ })
+	if consumed != 0 {
+		t.Errorf("Expected no results, got %d", consumed)
+	}
+}
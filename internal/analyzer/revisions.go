@@ -0,0 +1,183 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"ganalyzer/pkg/types"
+)
+
+const (
+	// RevisionsAll analyzes every ref, including remote-tracking branches and tags
+	RevisionsAll = "all"
+	// RevisionsDefault analyzes the default branch only
+	RevisionsDefault = "default"
+	// RevisionsMainline follows the first parent of the default branch, so
+	// merged feature branches count only through their merge commits
+	RevisionsMainline = "mainline"
+)
+
+// Candidate default branches when the repository has no origin/HEAD
+var defaultBranchNames = []string{"main", "master"}
+
+// revisionSelection is a revision spec resolved for one repository
+type revisionSelection struct {
+	// args are passed to git log; nil means there is nothing to analyze
+	args []string
+	refs []types.ResolvedRef
+}
+
+// LoadRevisionSpecs reads per-repository revision specs from a JSON object
+// mapping a repository name, path or path suffix to a spec:
+//
+//	{"api": "mainline", "legacy/web": "v1.0..v2.0", "/src/tools": "refs/heads/release/*"}
+func LoadRevisionSpecs(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read revisions file: %w", err)
+	}
+
+	specs := make(map[string]string)
+	if err := json.Unmarshal(data, &specs); err != nil {
+		return nil, fmt.Errorf("failed to parse revisions file %s: %w", path, err)
+	}
+	for key, spec := range specs {
+		if strings.TrimSpace(spec) == "" {
+			return nil, fmt.Errorf("revisions file %s: empty spec for %q", path, key)
+		}
+	}
+	return specs, nil
+}
+
+// revisionSpec returns the spec configured for a repository. A per-repository
+// entry matching its full path wins over one matching a path suffix or name.
+func (a *Analyzer) revisionSpec(repoPath string) string {
+	slashPath := filepath.ToSlash(repoPath)
+	best, bestLength := a.revisions, -1
+	for key, spec := range a.repositoryRevisions {
+		key = strings.TrimSuffix(filepath.ToSlash(key), "/")
+		if (slashPath == key || strings.HasSuffix(slashPath, "/"+key)) && len(key) > bestLength {
+			best, bestLength = spec, len(key)
+		}
+	}
+	if best == "" {
+		return RevisionsAll
+	}
+	return best
+}
+
+// resolveRevisions turns a spec into git log arguments and the refs they stand for.
+// Besides the keywords above, a spec containing a wildcard is a ref glob
+// ("refs/heads/release/*") and anything else is passed to git as revisions
+// or ranges ("v1.0..v2.0", "main ^stale").
+func resolveRevisions(repoPath, spec string) (revisionSelection, error) {
+	switch {
+	case spec == RevisionsAll:
+		refs, err := listRefs(repoPath)
+		return revisionSelection{args: []string{"--all"}, refs: refs}, err
+
+	case spec == RevisionsDefault || spec == RevisionsMainline:
+		branch := defaultBranch(repoPath)
+		commit, ok := resolveCommit(repoPath, branch)
+		if !ok {
+			// No commits yet
+			return revisionSelection{}, nil
+		}
+		args := []string{branch}
+		if spec == RevisionsMainline {
+			args = []string{"--first-parent", branch}
+		}
+		return revisionSelection{args: args, refs: []types.ResolvedRef{{Ref: branch, Commit: commit}}}, nil
+
+	case strings.ContainsAny(spec, "*?["):
+		pattern := spec
+		if !strings.HasPrefix(pattern, "refs/") {
+			pattern = "refs/" + pattern
+		}
+		refs, err := listRefs(repoPath, pattern)
+		return revisionSelection{args: []string{"--glob=" + pattern}, refs: refs}, err
+
+	default:
+		selection := revisionSelection{args: strings.Fields(spec)}
+		for _, arg := range selection.args {
+			for _, ref := range rangeEndpoints(arg) {
+				commit, ok := resolveCommit(repoPath, strings.TrimPrefix(ref, "^"))
+				if !ok {
+					return revisionSelection{}, fmt.Errorf("unknown revision %q", ref)
+				}
+				selection.refs = append(selection.refs, types.ResolvedRef{Ref: ref, Commit: commit})
+			}
+		}
+		return selection, nil
+	}
+}
+
+// rangeEndpoints splits "a..b" and "a...b" into their endpoints, an empty
+// endpoint standing for HEAD as in git
+func rangeEndpoints(arg string) []string {
+	for _, separator := range []string{"...", ".."} {
+		if from, to, ok := strings.Cut(arg, separator); ok {
+			if from == "" {
+				from = "HEAD"
+			}
+			if to == "" {
+				to = "HEAD"
+			}
+			return []string{from, to}
+		}
+	}
+	return []string{arg}
+}
+
+// defaultBranch returns the branch origin/HEAD points to, preferring the local
+// branch of that name since it may be ahead of the remote, then main or
+// master, and finally whatever HEAD is
+func defaultBranch(repoPath string) string {
+	output, err := exec.Command("git", "-C", repoPath, "symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD").Output()
+	if err == nil {
+		remote := strings.TrimSpace(string(output))
+		local := strings.TrimPrefix(remote, "origin/")
+		if _, ok := resolveCommit(repoPath, "refs/heads/"+local); ok {
+			return local
+		}
+		return remote
+	}
+
+	for _, name := range defaultBranchNames {
+		if _, ok := resolveCommit(repoPath, "refs/heads/"+name); ok {
+			return name
+		}
+	}
+	return "HEAD"
+}
+
+// resolveCommit returns the commit a revision points to
+func resolveCommit(repoPath, revision string) (string, bool) {
+	output, err := exec.Command("git", "-C", repoPath, "rev-parse", "--verify", "--quiet", revision+"^{commit}").Output()
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(output)), true
+}
+
+// listRefs returns the refs matching patterns, or all refs, with the commits
+// they point to; annotated tags are peeled
+func listRefs(repoPath string, patterns ...string) ([]types.ResolvedRef, error) {
+	args := []string{"-C", repoPath, "for-each-ref", "--format=%(refname)%00%(if)%(*objectname)%(then)%(*objectname)%(else)%(objectname)%(end)"}
+	output, err := exec.Command("git", append(args, patterns...)...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git for-each-ref failed: %w", err)
+	}
+
+	refs := make([]types.ResolvedRef, 0)
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if name, commit, ok := strings.Cut(line, "\x00"); ok {
+			refs = append(refs, types.ResolvedRef{Ref: name, Commit: commit})
+		}
+	}
+	return refs, nil
+}
@@ -0,0 +1,148 @@
+package analyzer
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAnalyzer_Revisions(t *testing.T) {
+	if !hasGit() {
+		t.Skip("Git not available, skipping integration test")
+	}
+
+	tempDir := createTestGitRepo(t)
+	output, err := exec.Command("git", "-C", tempDir, "symbolic-ref", "--short", "HEAD").Output()
+	if err != nil {
+		t.Fatalf("git symbolic-ref failed: %v", err)
+	}
+	mainBranch := strings.TrimSpace(string(output))
+
+	// main: two commits tagged v1, then a merge of feature; stale is never merged
+	steps := [][]string{
+		{"tag", "v1"},
+		{"checkout", "-q", "-b", "feature"},
+	}
+	for _, step := range steps {
+		if err := runCmd(tempDir, "git", step...); err != nil {
+			t.Fatalf("git %v failed: %v", step, err)
+		}
+	}
+	commitAs(t, tempDir, "Feature Dev", "feature@example.com", "feature.txt", "feature\n")
+	if err := runCmd(tempDir, "git", "checkout", "-q", "-b", "stale", "v1"); err != nil {
+		t.Fatalf("git checkout failed: %v", err)
+	}
+	commitAs(t, tempDir, "Stale Dev", "stale@example.com", "stale.txt", "stale\n")
+	steps = [][]string{
+		{"checkout", "-q", mainBranch},
+		{"merge", "-q", "--no-ff", "-m", "Merge feature", "feature"},
+	}
+	for _, step := range steps {
+		if err := runCmd(tempDir, "git", step...); err != nil {
+			t.Fatalf("git %v failed: %v", step, err)
+		}
+	}
+
+	tests := []struct {
+		spec     string
+		expected map[string]int
+		refs     []string
+	}{
+		{RevisionsAll, map[string]int{"Test User": 3, "Feature Dev": 1, "Stale Dev": 1}, nil},
+		{RevisionsDefault, map[string]int{"Test User": 3, "Feature Dev": 1}, []string{mainBranch}},
+		{RevisionsMainline, map[string]int{"Test User": 3}, []string{mainBranch}},
+		{"heads/st*", map[string]int{"Test User": 2, "Stale Dev": 1}, []string{"refs/heads/stale"}},
+		{"v1.." + mainBranch, map[string]int{"Test User": 1, "Feature Dev": 1}, []string{"v1", mainBranch}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			repo, err := NewAnalyzerWithOptions(Options{Revisions: tt.spec}).AnalyzeRepository(tempDir)
+			if err != nil {
+				t.Fatalf("AnalyzeRepository failed: %v", err)
+			}
+
+			if len(repo.Contributors) != len(tt.expected) {
+				t.Errorf("Expected %d contributors, got %d", len(tt.expected), len(repo.Contributors))
+			}
+			for name, commits := range tt.expected {
+				if stats := repo.Contributors[name]; stats == nil || stats.CommitCount != commits {
+					t.Errorf("Expected %d commits for %s, got %+v", commits, name, stats)
+				}
+			}
+
+			if repo.Revision != tt.spec {
+				t.Errorf("Expected revision %q to be recorded, got %q", tt.spec, repo.Revision)
+			}
+			if tt.refs == nil {
+				return
+			}
+			if len(repo.Refs) != len(tt.refs) {
+				t.Fatalf("Expected refs %v, got %+v", tt.refs, repo.Refs)
+			}
+			for i, ref := range tt.refs {
+				if repo.Refs[i].Ref != ref || len(repo.Refs[i].Commit) < 40 {
+					t.Errorf("Expected resolved ref %s, got %+v", ref, repo.Refs[i])
+				}
+			}
+		})
+	}
+
+	if _, err := NewAnalyzerWithOptions(Options{Revisions: "nope..v1"}).AnalyzeRepository(tempDir); err == nil {
+		t.Error("Expected error for an unknown revision")
+	}
+}
+
+func TestAnalyzer_RevisionSpec(t *testing.T) {
+	a := NewAnalyzerWithOptions(Options{
+		Revisions: RevisionsMainline,
+		RepositoryRevisions: map[string]string{
+			"api":             "v1..v2",
+			"/src/legacy/api": RevisionsDefault,
+			"tools/":          RevisionsAll,
+		},
+	})
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/src/api", "v1..v2"},
+		{"/src/legacy/api", RevisionsDefault},
+		{"/src/tools", RevisionsAll},
+		{"/src/web", RevisionsMainline},
+		{"/src/myapi", RevisionsMainline},
+	}
+
+	for _, tt := range tests {
+		if got := a.revisionSpec(tt.path); got != tt.expected {
+			t.Errorf("revisionSpec(%q) = %q, expected %q", tt.path, got, tt.expected)
+		}
+	}
+
+	if got := NewAnalyzer().revisionSpec("/src/api"); got != RevisionsAll {
+		t.Errorf("Expected all refs by default, got %q", got)
+	}
+}
+
+func TestLoadRevisionSpecs(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.json")
+	if err := os.WriteFile(valid, []byte(`{"api": "mainline"}`), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	specs, err := LoadRevisionSpecs(valid)
+	if err != nil || specs["api"] != RevisionsMainline {
+		t.Errorf("Expected spec for api, got %v (%v)", specs, err)
+	}
+
+	empty := filepath.Join(dir, "empty.json")
+	if err := os.WriteFile(empty, []byte(`{"api": " "}`), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if _, err := LoadRevisionSpecs(empty); err == nil {
+		t.Error("Expected error for an empty spec")
+	}
+}
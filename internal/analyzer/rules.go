@@ -0,0 +1,216 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"ganalyzer/pkg/types"
+)
+
+// Prefix of contributor keys produced by merge rules, so they never collide
+// with name or email keys
+const ruleKeyPrefix = "="
+
+// Separator between a contributor key and the never-merge entry that splits it
+const splitKeySeparator = "\x00"
+
+// IdentityRules are manual overrides for contributor identity, loaded from JSON:
+//
+//	{
+//	  "merge": [
+//	    {"name": "Jan Novák", "match": ["Honza", "jan.novak@acme.com", "jnovak"]}
+//	  ],
+//	  "never_merge": [
+//	    ["jan.novak@acme.com", "jan.novak@interns.acme.com"]
+//	  ]
+//	}
+//
+// Entries containing "@" match emails case-insensitively; other entries match
+// names after normalization. A merge rule forces every matching identity, and
+// its own name, into one contributor displayed under that name; a rule with a
+// single match simply sets a canonical display name. Identities listed in a
+// never_merge group are kept apart from each other by every identity stage.
+type IdentityRules struct {
+	Merge      []MergeRule `json:"merge"`
+	NeverMerge [][]string  `json:"never_merge"`
+
+	normalizer  *NameNormalizer
+	mergeEmails map[string]int
+	mergeNames  map[string]int
+	// separate maps a never-merge entry to itself in canonical form and
+	// conflicts lists the entries each one must stay apart from
+	separate  map[string]string
+	conflicts map[string]map[string]bool
+}
+
+// MergeRule forces all identities in Match into one contributor named Name
+type MergeRule struct {
+	Name  string   `json:"name"`
+	Match []string `json:"match"`
+}
+
+// LoadIdentityRules reads identity rules from a JSON file
+func LoadIdentityRules(path string) (*IdentityRules, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open identity rules: %w", err)
+	}
+	defer file.Close()
+
+	rules, err := ParseIdentityRules(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse identity rules %s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// ParseIdentityRules decodes and validates identity rules
+func ParseIdentityRules(r io.Reader) (*IdentityRules, error) {
+	rules := &IdentityRules{}
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(rules); err != nil {
+		return nil, err
+	}
+
+	if err := rules.compile(); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *IdentityRules) compile() error {
+	r.normalizer = NewNameNormalizer()
+	r.mergeEmails = make(map[string]int)
+	r.mergeNames = make(map[string]int)
+	r.separate = make(map[string]string)
+	r.conflicts = make(map[string]map[string]bool)
+
+	for index, rule := range r.Merge {
+		if strings.TrimSpace(rule.Name) == "" {
+			return fmt.Errorf("merge rule %d has no name", index+1)
+		}
+		for _, entry := range append([]string{rule.Name}, rule.Match...) {
+			entries, key := r.mergeNames, r.entryKey(entry)
+			if isEmailEntry(entry) {
+				entries = r.mergeEmails
+			}
+			if previous, exists := entries[key]; exists && previous != index {
+				return fmt.Errorf("%q matches both %q and %q", entry, r.Merge[previous].Name, rule.Name)
+			}
+			entries[key] = index
+		}
+	}
+
+	for index, group := range r.NeverMerge {
+		if len(group) < 2 {
+			return fmt.Errorf("never_merge group %d needs at least two entries", index+1)
+		}
+		for _, entry := range group {
+			key := r.entryKey(entry)
+			r.separate[key] = key
+			if r.conflicts[key] == nil {
+				r.conflicts[key] = make(map[string]bool)
+			}
+			for _, other := range group {
+				if otherKey := r.entryKey(other); otherKey != key {
+					r.conflicts[key][otherKey] = true
+				}
+			}
+		}
+		for _, entry := range group[1:] {
+			if x, y := r.mergeRule(group[0]), r.mergeRule(entry); x >= 0 && x == y {
+				return fmt.Errorf("never_merge group %d contradicts merge rule %q", index+1, r.Merge[x].Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+func isEmailEntry(entry string) bool {
+	return strings.Contains(entry, "@")
+}
+
+// entryKey is the canonical form an entry is matched by
+func (r *IdentityRules) entryKey(entry string) string {
+	entry = strings.TrimSpace(entry)
+	if isEmailEntry(entry) {
+		return strings.ToLower(entry)
+	}
+	return r.normalizer.NormalizeName(entry)
+}
+
+// mergeRule returns the index of the merge rule a single entry belongs to, or -1
+func (r *IdentityRules) mergeRule(entry string) int {
+	entries := r.mergeNames
+	if isEmailEntry(entry) {
+		entries = r.mergeEmails
+	}
+	if index, ok := entries[r.entryKey(entry)]; ok {
+		return index
+	}
+	return -1
+}
+
+// match returns the merge rule covering an identity, emails taking precedence
+func (r *IdentityRules) match(name, email string) *MergeRule {
+	if r == nil {
+		return nil
+	}
+	if index, ok := r.mergeEmails[strings.ToLower(email)]; ok && email != "" {
+		return &r.Merge[index]
+	}
+	if index, ok := r.mergeNames[r.normalizer.NormalizeName(name)]; ok {
+		return &r.Merge[index]
+	}
+	return nil
+}
+
+// splitKey returns the never-merge entry an identity falls under, or ""
+func (r *IdentityRules) splitKey(name, email string) string {
+	if r == nil {
+		return ""
+	}
+	if key, ok := r.separate[strings.ToLower(email)]; ok && email != "" {
+		return key
+	}
+	return r.separate[r.normalizer.NormalizeName(name)]
+}
+
+// conflict reports whether two contributors carry identities that a
+// never_merge group keeps apart
+func (r *IdentityRules) conflict(x, y *types.ContributorStats) bool {
+	if r == nil || len(r.conflicts) == 0 {
+		return false
+	}
+
+	keysY := r.contributorEntries(y)
+	for keyX := range r.contributorEntries(x) {
+		for other := range r.conflicts[keyX] {
+			if keysY[other] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// contributorEntries returns the never-merge entries matching any identity of stats
+func (r *IdentityRules) contributorEntries(stats *types.ContributorStats) map[string]bool {
+	entries := make(map[string]bool)
+	for _, email := range contributorEmails(stats) {
+		if key, ok := r.separate[strings.ToLower(email)]; ok {
+			entries[key] = true
+		}
+	}
+	for _, name := range contributorNames(stats) {
+		if key, ok := r.separate[r.normalizer.NormalizeName(name)]; ok {
+			entries[key] = true
+		}
+	}
+	return entries
+}
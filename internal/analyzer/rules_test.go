@@ -0,0 +1,95 @@
+package analyzer
+
+import (
+	"strings"
+	"testing"
+
+	"ganalyzer/pkg/types"
+)
+
+const testRules = `{
+  "merge": [
+    {"name": "Jan Novák", "match": ["Honza", "JNovak@Acme.com"]}
+  ],
+  "never_merge": [
+    ["jan.novak@acme.com", "jan.novak@interns.acme.com"]
+  ]
+}`
+
+func TestParseIdentityRules_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"malformed JSON", `{"merge": [`},
+		{"unknown field", `{"split": []}`},
+		{"rule without name", `{"merge": [{"match": ["x"]}]}`},
+		{"entry in two rules", `{"merge": [{"name": "A", "match": ["x@y.z"]}, {"name": "B", "match": ["X@Y.z"]}]}`},
+		{"single entry group", `{"never_merge": [["a@b.c"]]}`},
+		{"contradicting rules", `{"merge": [{"name": "A", "match": ["a@b.c", "d@e.f"]}], "never_merge": [["a@b.c", "D@E.F"]]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseIdentityRules(strings.NewReader(tt.input)); err == nil {
+				t.Errorf("Expected %s to be rejected", tt.name)
+			}
+		})
+	}
+}
+
+func TestIdentityRules_Match(t *testing.T) {
+	rules, err := ParseIdentityRules(strings.NewReader(testRules))
+	if err != nil {
+		t.Fatalf("ParseIdentityRules failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		email    string
+		expected string
+	}{
+		{"honza", "honza@home.net", "Jan Novák"},
+		{"Someone", "jnovak@acme.com", "Jan Novák"},
+		{"Jan Novak", "jan@home.net", "Jan Novák"},
+		{"Jane Doe", "jane@acme.com", ""},
+	}
+
+	for _, tt := range tests {
+		rule := rules.match(tt.name, tt.email)
+		got := ""
+		if rule != nil {
+			got = rule.Name
+		}
+		if got != tt.expected {
+			t.Errorf("match(%q, %q) = %q, expected %q", tt.name, tt.email, got, tt.expected)
+		}
+	}
+
+	var none *IdentityRules
+	if none.match("Honza", "") != nil || none.splitKey("Honza", "") != "" {
+		t.Error("Expected nil rules to match nothing")
+	}
+}
+
+func TestIdentityRules_Conflict(t *testing.T) {
+	rules, err := ParseIdentityRules(strings.NewReader(`{"never_merge": [["jan.novak@acme.com", "jan.novak@interns.acme.com"]]}`))
+	if err != nil {
+		t.Fatalf("ParseIdentityRules failed: %v", err)
+	}
+
+	if got := rules.splitKey("Jan Novák", "Jan.Novak@Acme.com"); got != "jan.novak@acme.com" {
+		t.Errorf("Expected split key for listed email, got %q", got)
+	}
+
+	staff := &types.ContributorStats{Name: "Jan Novák", Emails: []string{"jan.novak@acme.com"}}
+	intern := &types.ContributorStats{Name: "Jan Novak", Emails: []string{"jan.novak@interns.acme.com"}}
+	other := &types.ContributorStats{Name: "Jan Novak", Emails: []string{"jan@home.net"}}
+
+	if !rules.conflict(staff, intern) || !rules.conflict(intern, staff) {
+		t.Error("Expected listed identities to conflict")
+	}
+	if rules.conflict(staff, other) {
+		t.Error("Expected unlisted identity not to conflict")
+	}
+}
@@ -0,0 +1,165 @@
+package formatter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"ganalyzer/pkg/types"
+)
+
+// maxSparklineBuckets limits the sparkline to the most recent buckets so the
+// table stays readable on long histories
+const maxSparklineBuckets = 24
+
+// sparkLevels are the bar heights of a sparkline, lowest first
+var sparkLevels = []rune("▁▂▃▄▅▆▇█")
+
+// activityRow is one (repository, contributor, bucket) entry of the long-form
+// activity series
+type activityRow struct {
+	Repository   string    `json:"repository"`
+	Path         string    `json:"path"`
+	Name         string    `json:"name"`
+	Email        string    `json:"email"`
+	Bucket       string    `json:"bucket"`
+	Start        time.Time `json:"start"`
+	Commits      int       `json:"commits"`
+	CoAuthored   int       `json:"co_authored"`
+	LinesAdded   int       `json:"lines_added"`
+	LinesDeleted int       `json:"lines_deleted"`
+}
+
+// activityRows returns the activity series of every repository's top
+// contributors in long form, or nil when no interval is configured
+func activityRows(repos []*types.Repository, config Config) []activityRow {
+	if config.Interval == "" {
+		return nil
+	}
+
+	rows := make([]activityRow, 0)
+	for _, repo := range repos {
+		for _, contributor := range repo.GetSortedContributors(config.SortBy, config.TopN) {
+			for _, bucket := range contributor.Series() {
+				rows = append(rows, activityRow{
+					Repository:   repo.Name,
+					Path:         repo.Path,
+					Name:         contributor.Name,
+					Email:        contributor.Email,
+					Bucket:       types.BucketLabel(bucket.Start, config.Interval),
+					Start:        bucket.Start,
+					Commits:      bucket.Commits,
+					CoAuthored:   bucket.CoAuthored,
+					LinesAdded:   bucket.LinesAdded,
+					LinesDeleted: bucket.LinesDeleted,
+				})
+			}
+		}
+	}
+	return rows
+}
+
+// formatActivityCSV writes one row per repository, contributor and bucket
+// with activity, ready for pivot tables and plotting
+func (f *Formatter) formatActivityCSV(repos []*types.Repository, config Config, writer io.Writer) error {
+	csvWriter := csv.NewWriter(writer)
+	defer csvWriter.Flush()
+
+	headers := []string{"Repository", "Path", "Name", "Email", "Bucket", "Start", "Commits", "Co-authored", "Lines Added", "Lines Deleted"}
+	if err := csvWriter.Write(headers); err != nil {
+		return err
+	}
+
+	for _, row := range activityRows(repos, config) {
+		record := []string{
+			row.Repository,
+			row.Path,
+			row.Name,
+			row.Email,
+			row.Bucket,
+			row.Start.Format(time.DateOnly),
+			strconv.Itoa(row.Commits),
+			strconv.Itoa(row.CoAuthored),
+			strconv.Itoa(row.LinesAdded),
+			strconv.Itoa(row.LinesDeleted),
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// activityTimeline returns the bucket starts the sparklines are drawn over:
+// the configured time window, or else the span of all recorded activity,
+// limited to the most recent maxSparklineBuckets
+func activityTimeline(stats *types.GlobalStats, config Config) []time.Time {
+	if config.Interval == "" {
+		return nil
+	}
+
+	var first, last time.Time
+	for _, contributor := range stats.Contributors {
+		for start := range contributor.Activity {
+			if first.IsZero() || start.Before(first) {
+				first = start
+			}
+			if start.After(last) {
+				last = start
+			}
+		}
+	}
+	if !config.Since.IsZero() {
+		first = types.BucketStart(config.Since, config.Interval)
+	}
+	if !config.Until.IsZero() {
+		last = types.BucketStart(config.Until, config.Interval)
+	}
+	if first.IsZero() || last.IsZero() {
+		return nil
+	}
+
+	timeline := make([]time.Time, 0)
+	for start := first; !start.After(last); start = types.NextBucket(start, config.Interval) {
+		timeline = append(timeline, start)
+	}
+	if len(timeline) > maxSparklineBuckets {
+		timeline = timeline[len(timeline)-maxSparklineBuckets:]
+	}
+	return timeline
+}
+
+// sparkline draws the commits authored or co-authored per bucket over the
+// timeline, scaled to the busiest bucket; buckets without commits are blank
+func sparkline(activity map[time.Time]*types.Activity, timeline []time.Time) string {
+	peak := 0
+	for _, start := range timeline {
+		if a, ok := activity[start]; ok {
+			peak = max(peak, a.Participated())
+		}
+	}
+
+	var line strings.Builder
+	for _, start := range timeline {
+		a, ok := activity[start]
+		if !ok || a.Participated() <= 0 {
+			line.WriteRune(' ')
+			continue
+		}
+		level := (a.Participated()*len(sparkLevels) + peak - 1) / peak
+		line.WriteRune(sparkLevels[level-1])
+	}
+	return line.String()
+}
+
+// describeTimeline renders the first and last bucket the sparklines cover
+func describeTimeline(timeline []time.Time, interval string) string {
+	if len(timeline) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s to %s, one bar per %s", types.BucketLabel(timeline[0], interval),
+		types.BucketLabel(timeline[len(timeline)-1], interval), interval)
+}
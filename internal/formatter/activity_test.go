@@ -0,0 +1,72 @@
+package formatter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"ganalyzer/pkg/types"
+)
+
+func TestSparkline(t *testing.T) {
+	jan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	mar := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	apr := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	activity := map[time.Time]*types.Activity{
+		jan: {CoAuthored: 1},
+		mar: {Commits: 8},
+		apr: {Commits: 3, CoAuthored: 1},
+	}
+	if got := sparkline(activity, []time.Time{jan, feb, mar, apr}); got != "▁ █▄" {
+		t.Errorf("sparkline() = %q, want %q", got, "▁ █▄")
+	}
+	if got := sparkline(nil, []time.Time{jan, feb}); got != "  " {
+		t.Errorf("sparkline() without activity = %q, want blanks", got)
+	}
+}
+
+func TestFormatter_Activity(t *testing.T) {
+	jan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mar := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	repo := types.NewRepository("/path/to/api")
+	repo.Contributors["Alice"] = &types.ContributorStats{Name: "Alice", Email: "alice@example.com"}
+	repo.Credit("aaa", types.CommitCredit{Key: "Alice", Commits: 1, LinesAdded: 10, LinesDeleted: 2, Bucket: jan})
+	repo.Credit("bbb", types.CommitCredit{Key: "Alice", Commits: 1, LinesAdded: 1, Bucket: mar})
+	repo.Contributors["Bob"] = &types.ContributorStats{Name: "Bob", Email: "bob@example.com"}
+	repo.Credit("ccc", types.CommitCredit{Key: "Alice", Commits: 1, LinesAdded: 1, Bucket: mar},
+		types.CommitCredit{Key: "Bob", CoAuthored: 1, Bucket: mar})
+	stats := types.NewGlobalStats()
+	stats.AddRepository(repo)
+
+	formatter := NewFormatter()
+	config := Config{OutputFormat: "csv", SortBy: "commits", Interval: types.IntervalMonth}
+
+	var buf bytes.Buffer
+	if err := formatter.Format(stats, config, &buf); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	want := "Repository,Path,Name,Email,Bucket,Start,Commits,Co-authored,Lines Added,Lines Deleted\n" +
+		"api,/path/to/api,Alice,alice@example.com,2024-01,2024-01-01,1,0,10,2\n" +
+		"api,/path/to/api,Alice,alice@example.com,2024-03,2024-03-01,2,0,2,0\n" +
+		"api,/path/to/api,Bob,bob@example.com,2024-03,2024-03-01,0,1,0,0\n"
+	if buf.String() != want {
+		t.Errorf("Unexpected activity CSV:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	config.OutputFormat = "table"
+	if err := formatter.Format(stats, config, &buf); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	output := buf.String()
+	if !strings.Contains(output, "Activity: 2024-01 to 2024-03, one bar per month") {
+		t.Errorf("Expected the sparkline range in the header, got:\n%s", output)
+	}
+	if !strings.Contains(output, "Trend") || !strings.Contains(output, "  ▄ █\n") {
+		t.Errorf("Expected a trend column, got:\n%s", output)
+	}
+}
@@ -0,0 +1,125 @@
+package formatter
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"ganalyzer/pkg/types"
+)
+
+// concentrationView is a repository's knowledge concentration and whether it
+// crosses a configured risk threshold
+type concentrationView struct {
+	types.Concentration
+	HighRisk bool `json:"high_risk"`
+	// RiskReasons name the thresholds that were crossed
+	RiskReasons []string `json:"risk_reasons,omitempty"`
+}
+
+func newConcentrationView(repo *types.Repository, config Config) *concentrationView {
+	if !config.Concentration {
+		return nil
+	}
+	view := &concentrationView{Concentration: repo.Concentration()}
+	view.RiskReasons = riskReasons(view.Concentration, config)
+	view.HighRisk = len(view.RiskReasons) > 0
+	return view
+}
+
+// riskReasons describes every risk threshold the measures reach. A repository
+// without files has no bus factor to judge.
+func riskReasons(c types.Concentration, config Config) []string {
+	reasons := make([]string, 0)
+	if c.Files > 0 && c.BusFactor <= config.RiskBusFactor {
+		reasons = append(reasons, fmt.Sprintf("bus factor %d <= %d", c.BusFactor, config.RiskBusFactor))
+	}
+	if config.RiskTopShare > 0 && c.TopShare >= config.RiskTopShare {
+		reasons = append(reasons, fmt.Sprintf("top share %.2f >= %.2f", c.TopShare, config.RiskTopShare))
+	}
+	if config.RiskGini > 0 && c.Gini >= config.RiskGini {
+		reasons = append(reasons, fmt.Sprintf("gini %.2f >= %.2f", c.Gini, config.RiskGini))
+	}
+	return reasons
+}
+
+// writeRepositorySummary lists the bus factor and concentration of every repository
+func (f *Formatter) writeRepositorySummary(writer io.Writer, repos []*types.Repository, config Config) error {
+	title := "Repository Summary:"
+	if _, err := fmt.Fprintf(writer, "\n%s\n%s\n\n", title, strings.Repeat("=", len(title))); err != nil {
+		return err
+	}
+
+	labels := repositoryLabels(repos)
+	views := make([]*concentrationView, len(repos))
+	nameWidth, topWidth := minNameWidth, len("Top Contributor")
+	for i, repo := range repos {
+		views[i] = newConcentrationView(repo, config)
+		nameWidth = max(nameWidth, len(labels[i]))
+		topWidth = max(topWidth, len(views[i].TopContributor))
+	}
+	nameWidth += namePadding
+
+	columns := []column{
+		{header: "Files", width: 6},
+		{header: "Bus Factor", width: 10},
+		{header: "Gini", width: 6},
+		{header: "Top Share", width: 9},
+		{header: "Top Contributor", width: topWidth},
+		{header: "Risk", width: 4},
+	}
+	headers := make([]string, len(columns))
+	dashes := make([]string, len(columns))
+	for i, col := range columns {
+		headers[i] = col.header
+		dashes[i] = strings.Repeat("-", len(col.header))
+	}
+	if err := writeTableRow(writer, columns, nameWidth, "Repository", headers); err != nil {
+		return err
+	}
+	if err := writeTableRow(writer, columns, nameWidth, strings.Repeat("-", nameWidth), dashes); err != nil {
+		return err
+	}
+
+	for i, view := range views {
+		risk := "-"
+		if view.HighRisk {
+			risk = "HIGH"
+		}
+		values := []string{
+			strconv.Itoa(view.Files),
+			strconv.Itoa(view.BusFactor),
+			strconv.FormatFloat(view.Gini, 'f', 2, 64),
+			strconv.FormatFloat(view.TopShare*100, 'f', 1, 64) + "%",
+			view.TopContributor,
+			risk,
+		}
+		if err := writeTableRow(writer, columns, nameWidth, labels[i], values); err != nil {
+			return err
+		}
+	}
+
+	if _, err := fmt.Fprintf(writer, "\n"); err != nil {
+		return err
+	}
+	for i, view := range views {
+		if view.HighRisk {
+			if _, err := fmt.Fprintf(writer, "High risk: %s (%s)\n", labels[i], strings.Join(view.RiskReasons, ", ")); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := fmt.Fprintf(writer, "Measured on %s; bus factor: contributors whose departure orphans more than half of the files\n", basisOf(views))
+	return err
+}
+
+// basisOf names what the measures of the views were computed from
+func basisOf(views []*concentrationView) string {
+	for _, view := range views {
+		if view.Basis == types.BasisOwnership {
+			return "lines owned at HEAD"
+		}
+	}
+	return "commits"
+}
@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"ganalyzer/pkg/types"
 )
@@ -26,6 +28,53 @@ type Config struct {
 	SortBy         string
 	NormalizeNames bool
 	ShowAliases    bool
+	// GroupBy is the contributor identity used by the analyzer: "name" or "email"
+	GroupBy string
+	// Since and Until bound the analyzed history; zero values mean unbounded
+	Since time.Time
+	Until time.Time
+	// BotMode is how detected bots are reported: "exclude", "flag",
+	// "separate", or "" when detection is off
+	BotMode string
+	// CoAuthors is the co-author credit policy; when set, co-authored commits get their own column
+	CoAuthors string
+	// Dedupe is the duplicate commit detection in use; when set, collapsed commits get their own column
+	Dedupe string
+	// PerRepository adds the top contributors of each repository to the
+	// report; CSV output becomes one row per repository and contributor
+	PerRepository bool
+	// MatrixMetric is the cell value of the matrix formats: "commits", "lines" or "share"
+	MatrixMetric string
+	// Ownership adds the lines owned at HEAD according to git blame and their
+	// share of the repository, or of all repositories, to the output
+	Ownership bool
+	// Concentration adds a repository summary with bus factor, Gini
+	// coefficient and top-1 share. Repositories reaching any Risk threshold
+	// are flagged: a bus factor of at most RiskBusFactor, or a top share or
+	// Gini coefficient of at least RiskTopShare or RiskGini when those are positive.
+	Concentration bool
+	RiskBusFactor int
+	RiskTopShare  float64
+	RiskGini      float64
+	// ActiveWithin, when positive, is the number of days within which
+	// reported contributors last committed; tenure columns are shown with it
+	ActiveWithin int
+	// Interval is the granularity of the activity series: "week", "month" or
+	// "quarter". It adds a trend column to the table and a series to JSON,
+	// and turns CSV into one row per repository, contributor and bucket.
+	Interval string
+	// Binary adds the number of binary file changes to the table and CSV,
+	// and BinaryBytes by how many bytes they grew and shrank
+	Binary      bool
+	BinaryBytes bool
+	// Languages adds a column of lines changed per language to the table and
+	// columns of lines added and deleted per language to CSV; LanguagesAll
+	// selects every language seen
+	Languages []string
+	// GeneratedAt and Options describe the run in the JSON metadata; a zero
+	// GeneratedAt means the time of formatting
+	GeneratedAt time.Time
+	Options     map[string]string
 }
 
 // Formatter handles output formatting for analysis results
@@ -38,26 +87,64 @@ func NewFormatter() *Formatter {
 
 // Format outputs the analysis results in the specified format
 func (f *Formatter) Format(stats *types.GlobalStats, config Config, writer io.Writer) error {
-	contributors := stats.GetSortedContributors(config.SortBy, config.TopN)
+	contributors, bots := selectContributors(stats, config)
+	config.Languages = resolveLanguages(config.Languages, stats)
 
 	switch config.OutputFormat {
 	case "json":
-		return f.formatJSON(contributors, stats.Repositories, writer)
+		return f.formatJSON(contributors, bots, stats, config, writer)
 	case "csv":
-		return f.formatCSV(contributors, config, writer)
+		if config.Interval != "" {
+			return f.formatActivityCSV(stats.Repositories, config, writer)
+		}
+		if config.PerRepository {
+			return f.formatRepositoryCSV(stats.Repositories, config, writer)
+		}
+		return f.formatCSV(append(contributors, bots...), config, stats.SurvivingLines(), writer)
 	case "table":
-		return f.formatTable(contributors, stats.Repositories, config, writer)
+		return f.formatTable(contributors, bots, stats, config, writer)
+	case "matrix":
+		return f.formatMatrixTable(newMatrix(stats, append(contributors, bots...), config.MatrixMetric), config, writer)
+	case "matrix-csv":
+		return f.formatMatrixCSV(newMatrix(stats, append(contributors, bots...), config.MatrixMetric), writer)
 	default:
 		return fmt.Errorf("unsupported output format: %s", config.OutputFormat)
 	}
 }
 
-func (f *Formatter) formatTable(contributors []*types.ContributorStats, repos []*types.Repository, config Config, writer io.Writer) error {
-	if err := f.writeHeader(writer, len(repos), repos); err != nil {
+// selectContributors returns the sorted contributors to report. When bots are
+// listed separately they are returned on their own, each list limited to TopN.
+func selectContributors(stats *types.GlobalStats, config Config) (contributors, bots []*types.ContributorStats) {
+	if config.BotMode != "separate" {
+		return stats.GetSortedContributors(config.SortBy, config.TopN), nil
+	}
+
+	contributors = make([]*types.ContributorStats, 0)
+	bots = make([]*types.ContributorStats, 0)
+	for _, contributor := range stats.GetSortedContributors(config.SortBy, 0) {
+		if contributor.IsBot {
+			bots = append(bots, contributor)
+		} else {
+			contributors = append(contributors, contributor)
+		}
+	}
+	return limit(contributors, config.TopN), limit(bots, config.TopN)
+}
+
+func limit(contributors []*types.ContributorStats, topN int) []*types.ContributorStats {
+	if topN > 0 && topN < len(contributors) {
+		return contributors[:topN]
+	}
+	return contributors
+}
+
+func (f *Formatter) formatTable(contributors, bots []*types.ContributorStats, stats *types.GlobalStats, config Config, writer io.Writer) error {
+	timeline := activityTimeline(stats, config)
+	if err := f.writeHeader(writer, stats, config, timeline); err != nil {
 		return err
 	}
 
-	if len(contributors) == 0 {
+	if len(contributors) == 0 && len(bots) == 0 {
 		_, err := fmt.Fprintf(writer, "No contributors found.\n")
 		return err
 	}
@@ -66,10 +153,56 @@ func (f *Formatter) formatTable(contributors []*types.ContributorStats, repos []
 		return err
 	}
 
-	return f.writeContributors(writer, contributors, config)
+	columns := tableColumns(config, timeline, stats.SurvivingLines())
+	if err := f.writeContributors(writer, contributors, config, columns); err != nil {
+		return err
+	}
+
+	if len(bots) > 0 {
+		if _, err := fmt.Fprintf(writer, "\nBots:\n=====\n\n"); err != nil {
+			return err
+		}
+		if err := f.writeContributors(writer, bots, config, columns); err != nil {
+			return err
+		}
+	}
+
+	if config.Concentration {
+		if err := f.writeRepositorySummary(writer, stats.Repositories, config); err != nil {
+			return err
+		}
+	}
+
+	if config.PerRepository {
+		return f.writeRepositoryBreakdown(writer, stats.Repositories, config, timeline)
+	}
+	return nil
 }
 
-func (f *Formatter) writeHeader(writer io.Writer, repoCount int, repos []*types.Repository) error {
+// writeRepositoryBreakdown lists the top contributors of every repository
+func (f *Formatter) writeRepositoryBreakdown(writer io.Writer, repos []*types.Repository, config Config, timeline []time.Time) error {
+	for _, repo := range repos {
+		title := fmt.Sprintf("Repository: %s (%s)", repo.Name, repo.Path)
+		if _, err := fmt.Fprintf(writer, "\n%s\n%s\n\n", title, strings.Repeat("=", len(title))); err != nil {
+			return err
+		}
+
+		contributors := repo.GetSortedContributors(config.SortBy, config.TopN)
+		if len(contributors) == 0 {
+			if _, err := fmt.Fprintf(writer, "No contributors found.\n"); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := f.writeContributors(writer, contributors, config, tableColumns(config, timeline, repo.SurvivingLines)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *Formatter) writeHeader(writer io.Writer, stats *types.GlobalStats, config Config, timeline []time.Time) error {
+	repos := stats.Repositories
 	if _, err := fmt.Fprintf(writer, "Git Repository Analysis\n"); err != nil {
 		return err
 	}
@@ -77,11 +210,64 @@ func (f *Formatter) writeHeader(writer io.Writer, repoCount int, repos []*types.
 		return err
 	}
 
-	if _, err := fmt.Fprintf(writer, "Found %d repositories:\n", repoCount); err != nil {
+	if window := describeWindow(config); window != "" {
+		if _, err := fmt.Fprintf(writer, "Time window: %s\n\n", window); err != nil {
+			return err
+		}
+	}
+
+	if activity := describeTimeline(timeline, config.Interval); activity != "" {
+		if _, err := fmt.Fprintf(writer, "Activity: %s\n\n", activity); err != nil {
+			return err
+		}
+	}
+
+	if _, err := fmt.Fprintf(writer, "Found %d repositories:\n", len(repos)); err != nil {
 		return err
 	}
 	for _, repo := range repos {
-		if _, err := fmt.Fprintf(writer, "  - %s (%s)\n", repo.Name, repo.Path); err != nil {
+		trend := ""
+		if len(timeline) > 0 {
+			trend = "  " + strings.TrimRight(sparkline(repo.Activity(), timeline), " ")
+		}
+		if _, err := fmt.Fprintf(writer, "  - %s (%s)%s%s\n", repo.Name, repo.Path, describeRevisions(repo), trend); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(writer, "\n"); err != nil {
+		return err
+	}
+
+	if err := f.writeSharedHistory(writer, stats); err != nil {
+		return err
+	}
+	return f.writeFilteredLines(writer, stats)
+}
+
+// writeFilteredLines lists how many changed lines each path filter left out
+// of the line statistics, largest first
+func (f *Formatter) writeFilteredLines(writer io.Writer, stats *types.GlobalStats) error {
+	filtered := stats.FilteredLines()
+	if len(filtered) == 0 {
+		return nil
+	}
+
+	filters := make([]string, 0, len(filtered))
+	for filter := range filtered {
+		filters = append(filters, filter)
+	}
+	sort.Slice(filters, func(i, j int) bool {
+		if filtered[filters[i]] != filtered[filters[j]] {
+			return filtered[filters[i]] > filtered[filters[j]]
+		}
+		return filters[i] < filters[j]
+	})
+
+	if _, err := fmt.Fprintf(writer, "Lines excluded from statistics:\n"); err != nil {
+		return err
+	}
+	for _, filter := range filters {
+		if _, err := fmt.Fprintf(writer, "  - %s: %d\n", filter, filtered[filter]); err != nil {
 			return err
 		}
 	}
@@ -89,6 +275,60 @@ func (f *Formatter) writeHeader(writer io.Writer, repoCount int, repos []*types.
 	return err
 }
 
+// writeSharedHistory lists forks and clones whose common commits were counted once
+func (f *Formatter) writeSharedHistory(writer io.Writer, stats *types.GlobalStats) error {
+	shared := stats.SharedHistory()
+	if len(shared) == 0 && stats.DuplicateCommits == 0 {
+		return nil
+	}
+
+	if _, err := fmt.Fprintf(writer, "Repositories sharing history:\n"); err != nil {
+		return err
+	}
+	for _, group := range shared {
+		names := make([]string, len(group))
+		for i, repo := range group {
+			names[i] = repo.Name
+		}
+		if _, err := fmt.Fprintf(writer, "  - %s\n", strings.Join(names, ", ")); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(writer, "Counted %d unique commits; %d duplicates across repositories were counted once\n\n",
+		stats.UniqueCommits(), stats.DuplicateCommits)
+	return err
+}
+
+// describeRevisions renders the refs a repository was analyzed at, or "" when
+// all refs were analyzed
+func describeRevisions(repo *types.Repository) string {
+	if repo.Revision == "" || repo.Revision == "all" {
+		return ""
+	}
+	refs := make([]string, len(repo.Refs))
+	for i, ref := range repo.Refs {
+		refs[i] = fmt.Sprintf("%s@%.7s", ref.Ref, ref.Commit)
+	}
+	if len(refs) == 0 {
+		return fmt.Sprintf(" [%s: no matching refs]", repo.Revision)
+	}
+	return fmt.Sprintf(" [%s: %s]", repo.Revision, strings.Join(refs, ", "))
+}
+
+// describeWindow renders the configured time window, or "" when the whole history is analyzed
+func describeWindow(config Config) string {
+	switch {
+	case !config.Since.IsZero() && !config.Until.IsZero():
+		return fmt.Sprintf("%s to %s", config.Since.Format(time.RFC3339), config.Until.Format(time.RFC3339))
+	case !config.Since.IsZero():
+		return fmt.Sprintf("since %s", config.Since.Format(time.RFC3339))
+	case !config.Until.IsZero():
+		return fmt.Sprintf("until %s", config.Until.Format(time.RFC3339))
+	default:
+		return ""
+	}
+}
+
 func (f *Formatter) writeContributorsHeader(writer io.Writer) error {
 	if _, err := fmt.Fprintf(writer, "Top Contributors:\n"); err != nil {
 		return err
@@ -97,15 +337,66 @@ func (f *Formatter) writeContributorsHeader(writer io.Writer) error {
 	return err
 }
 
-func (f *Formatter) writeContributors(writer io.Writer, contributors []*types.ContributorStats, config Config) error {
+func (f *Formatter) writeContributors(writer io.Writer, contributors []*types.ContributorStats, config Config, columns []column) error {
 	nameWidth := f.calculateNameWidth(contributors, config)
-	format := fmt.Sprintf("%%-%ds %%8s %%10s %%10s %%12s\n", nameWidth)
 
-	if err := f.writeTableHeader(writer, format, nameWidth); err != nil {
+	if err := f.writeTableHeader(writer, columns, nameWidth); err != nil {
 		return err
 	}
 
-	return f.writeContributorRows(writer, contributors, config, format)
+	return f.writeContributorRows(writer, contributors, config, columns, nameWidth)
+}
+
+// column is a right-aligned table column following the contributor name
+type column struct {
+	header string
+	width  int
+	value  func(*types.ContributorStats) string
+}
+
+// tableColumns returns the table columns enabled by config; the trend column
+// draws activity over timeline and ownership shares are of surviving lines
+func tableColumns(config Config, timeline []time.Time, surviving int) []column {
+	columns := []column{
+		{"Commits", 8, func(c *types.ContributorStats) string { return strconv.Itoa(c.CommitCount) }},
+		{"Lines+", 10, func(c *types.ContributorStats) string { return strconv.Itoa(c.LinesAdded) }},
+		{"Lines-", 10, func(c *types.ContributorStats) string { return strconv.Itoa(c.LinesDeleted) }},
+		{"Total Lines", 12, func(c *types.ContributorStats) string { return strconv.Itoa(c.LinesChanged) }},
+	}
+	if config.Ownership {
+		columns = append(columns,
+			column{"Owned Lines", 11, func(c *types.ContributorStats) string { return strconv.Itoa(c.OwnedLines) }},
+			column{"Ownership", 9, func(c *types.ContributorStats) string { return ownershipShare(c.OwnedLines, surviving) + "%" }},
+		)
+	}
+	if showBinary(config) {
+		columns = append(columns, column{"Binary Files", 12, func(c *types.ContributorStats) string { return strconv.Itoa(c.BinaryFiles) }})
+	}
+	if config.BinaryBytes {
+		columns = append(columns,
+			column{"Binary+", 10, func(c *types.ContributorStats) string { return formatBytes(c.BinaryBytesAdded) }},
+			column{"Binary-", 10, func(c *types.ContributorStats) string { return formatBytes(c.BinaryBytesDeleted) }},
+		)
+	}
+	if config.CoAuthors != "" {
+		columns = append(columns, column{"Co-authored", 12, func(c *types.ContributorStats) string { return strconv.Itoa(c.CoAuthoredCommits) }})
+	}
+	if config.Dedupe != "" {
+		columns = append(columns, column{"Collapsed", 10, func(c *types.ContributorStats) string { return strconv.Itoa(c.CollapsedCommits) }})
+	}
+	if showTenure(config) {
+		columns = append(columns,
+			column{"First Commit", 12, func(c *types.ContributorStats) string { return formatDate(c.FirstCommit) }},
+			column{"Last Commit", 12, func(c *types.ContributorStats) string { return formatDate(c.LastCommit) }},
+			column{"Active Days", 11, func(c *types.ContributorStats) string { return strconv.Itoa(c.ActiveDays) }},
+			column{"Tenure", 8, func(c *types.ContributorStats) string { return strconv.Itoa(c.TenureDays) }},
+			column{"Longest Gap", 11, func(c *types.ContributorStats) string { return strconv.Itoa(c.LongestGapDays) }},
+		)
+	}
+	if config.Interval != "" {
+		columns = append(columns, column{"Trend", max(len(timeline), len("Trend")), func(c *types.ContributorStats) string { return sparkline(c.Activity, timeline) }})
+	}
+	return append(columns, languageColumns(config.Languages)...)
 }
 
 func (f *Formatter) calculateNameWidth(contributors []*types.ContributorStats, config Config) int {
@@ -120,78 +411,257 @@ func (f *Formatter) calculateNameWidth(contributors []*types.ContributorStats, c
 }
 
 func (f *Formatter) formatContributorName(contributor *types.ContributorStats, config Config) string {
-	if config.ShowAliases && config.NormalizeNames && len(contributor.Aliases) > 0 {
-		return fmt.Sprintf("%s (aliases: %s)", contributor.Name, strings.Join(contributor.Aliases, ", "))
+	name := contributor.Name
+	if contributor.IsBot && !strings.HasSuffix(strings.ToLower(name), "[bot]") {
+		name += " [bot]"
+	}
+	if showAliases(config) && len(contributor.Aliases) > 0 {
+		return fmt.Sprintf("%s (aliases: %s)", name, strings.Join(contributor.Aliases, ", "))
+	}
+	return name
+}
+
+// ownershipShare renders owned lines as a percentage of the surviving lines
+func ownershipShare(owned, surviving int) string {
+	if surviving == 0 {
+		return "0.0"
+	}
+	return strconv.FormatFloat(float64(owned)*100/float64(surviving), 'f', 1, 64)
+}
+
+// formatBytes renders a byte count with a binary unit, such as "1.5 MiB"
+func formatBytes(bytes int) string {
+	const unit = 1024
+	if bytes < unit {
+		return strconv.Itoa(bytes) + " B"
+	}
+	value, exponent := float64(bytes)/unit, 0
+	for value >= unit && exponent < 4 {
+		value /= unit
+		exponent++
+	}
+	return strconv.FormatFloat(value, 'f', 1, 64) + " " + string("KMGTP"[exponent]) + "iB"
+}
+
+// showBinary reports whether binary file changes are reported: when
+// requested, when their sizes are, or when sorting by them
+func showBinary(config Config) bool {
+	return config.Binary || config.BinaryBytes || config.SortBy == "binary"
+}
+
+// showTenure reports whether commit dates and active days are reported: when
+// sorting by one of them or filtering by recent activity
+func showTenure(config Config) bool {
+	switch config.SortBy {
+	case "first-commit", "last-commit", "active-days", "tenure", "longest-gap":
+		return true
 	}
-	return contributor.Name
+	return config.ActiveWithin > 0
 }
 
-func (f *Formatter) writeTableHeader(writer io.Writer, format string, nameWidth int) error {
-	if _, err := fmt.Fprintf(writer, format, "Name", "Commits", "Lines+", "Lines-", "Total Lines"); err != nil {
+// showWindow reports whether the analyzed time window is bounded, so CSV rows
+// record it like the table header and JSON metadata do
+func showWindow(config Config) bool {
+	return !config.Since.IsZero() || !config.Until.IsZero()
+}
+
+// formatTime renders t in RFC 3339 for machine readable output, or "" when it is unknown
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// formatDate renders the UTC date of t, or "-" when it is unknown
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.UTC().Format(time.DateOnly)
+}
+
+// showAliases reports whether aliases were requested and can exist,
+// which requires either name normalization or grouping by email
+func showAliases(config Config) bool {
+	return config.ShowAliases && (config.NormalizeNames || config.GroupBy == "email")
+}
+
+func (f *Formatter) writeTableHeader(writer io.Writer, columns []column, nameWidth int) error {
+	headers := make([]string, len(columns))
+	dashes := make([]string, len(columns))
+	for i, col := range columns {
+		headers[i] = col.header
+		dashes[i] = strings.Repeat("-", len(col.header))
+	}
+
+	if err := writeTableRow(writer, columns, nameWidth, "Name", headers); err != nil {
 		return err
 	}
-	_, err := fmt.Fprintf(writer, format, strings.Repeat("-", nameWidth), "-------", "------", "------", "-----------")
-	return err
+	return writeTableRow(writer, columns, nameWidth, strings.Repeat("-", nameWidth), dashes)
 }
 
-func (f *Formatter) writeContributorRows(writer io.Writer, contributors []*types.ContributorStats, config Config, format string) error {
+func (f *Formatter) writeContributorRows(writer io.Writer, contributors []*types.ContributorStats, config Config, columns []column, nameWidth int) error {
 	for _, contributor := range contributors {
-		name := f.formatContributorName(contributor, config)
-		if _, err := fmt.Fprintf(writer, format,
-			name,
-			strconv.Itoa(contributor.CommitCount),
-			strconv.Itoa(contributor.LinesAdded),
-			strconv.Itoa(contributor.LinesDeleted),
-			strconv.Itoa(contributor.LinesChanged),
-		); err != nil {
+		values := make([]string, len(columns))
+		for i, col := range columns {
+			values[i] = col.value(contributor)
+		}
+		if err := writeTableRow(writer, columns, nameWidth, f.formatContributorName(contributor, config), values); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (f *Formatter) formatJSON(contributors []*types.ContributorStats, repos []*types.Repository, writer io.Writer) error {
-	data := struct {
-		Repositories []*types.Repository       `json:"repositories"`
-		Contributors []*types.ContributorStats `json:"contributors"`
-	}{
-		Repositories: repos,
-		Contributors: contributors,
+// writeTableRow writes a left-aligned name followed by right-aligned column values
+func writeTableRow(writer io.Writer, columns []column, nameWidth int, name string, values []string) error {
+	var row strings.Builder
+	fmt.Fprintf(&row, "%-*s", nameWidth, name)
+	for i, col := range columns {
+		fmt.Fprintf(&row, " %*s", col.width, values[i])
 	}
+	row.WriteString("\n")
+
+	_, err := io.WriteString(writer, row.String())
+	return err
+}
 
+func (f *Formatter) formatJSON(contributors, bots []*types.ContributorStats, stats *types.GlobalStats, config Config, writer io.Writer) error {
 	encoder := json.NewEncoder(writer)
 	encoder.SetIndent("", "  ")
-	return encoder.Encode(data)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(newReport(contributors, bots, stats, config))
 }
 
-func (f *Formatter) formatCSV(contributors []*types.ContributorStats, config Config, writer io.Writer) error {
+// sharedHistoryPaths returns the paths of each group of repositories sharing history
+func sharedHistoryPaths(stats *types.GlobalStats) [][]string {
+	var groups [][]string
+	for _, group := range stats.SharedHistory() {
+		paths := make([]string, len(group))
+		for i, repo := range group {
+			paths[i] = repo.Path
+		}
+		groups = append(groups, paths)
+	}
+	return groups
+}
+
+func (f *Formatter) formatCSV(contributors []*types.ContributorStats, config Config, surviving int, writer io.Writer) error {
 	csvWriter := csv.NewWriter(writer)
 	defer csvWriter.Flush()
 
-	headers := []string{"Name", "Email", "Commits", "Lines Added", "Lines Deleted", "Total Lines"}
-	if config.ShowAliases && config.NormalizeNames {
-		headers = append(headers, "Aliases")
-	}
-	if err := csvWriter.Write(headers); err != nil {
+	if err := csvWriter.Write(csvHeaders(config)); err != nil {
 		return err
 	}
 
 	for _, contributor := range contributors {
-		record := []string{
-			contributor.Name,
-			contributor.Email,
-			strconv.Itoa(contributor.CommitCount),
-			strconv.Itoa(contributor.LinesAdded),
-			strconv.Itoa(contributor.LinesDeleted),
-			strconv.Itoa(contributor.LinesChanged),
-		}
-		if config.ShowAliases && config.NormalizeNames {
-			record = append(record, strings.Join(contributor.Aliases, "; "))
-		}
-		if err := csvWriter.Write(record); err != nil {
+		if err := csvWriter.Write(csvRecord(contributor, config, surviving)); err != nil {
 			return err
 		}
 	}
 
 	return nil
 }
+
+// formatRepositoryCSV writes one row per (repository, contributor) pair, in
+// long form so the data can be pivoted
+func (f *Formatter) formatRepositoryCSV(repos []*types.Repository, config Config, writer io.Writer) error {
+	csvWriter := csv.NewWriter(writer)
+	defer csvWriter.Flush()
+
+	if err := csvWriter.Write(append([]string{"Repository", "Path"}, csvHeaders(config)...)); err != nil {
+		return err
+	}
+
+	for _, repo := range repos {
+		for _, contributor := range repo.GetSortedContributors(config.SortBy, config.TopN) {
+			record := append([]string{repo.Name, repo.Path}, csvRecord(contributor, config, repo.SurvivingLines)...)
+			if err := csvWriter.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func csvHeaders(config Config) []string {
+	headers := []string{"Name", "Email", "Commits", "Lines Added", "Lines Deleted", "Total Lines"}
+	if config.Ownership {
+		headers = append(headers, "Owned Lines", "Ownership %")
+	}
+	if showBinary(config) {
+		headers = append(headers, "Binary Files")
+	}
+	if config.BinaryBytes {
+		headers = append(headers, "Binary Bytes Added", "Binary Bytes Deleted")
+	}
+	if showAliases(config) {
+		headers = append(headers, "Aliases")
+	}
+	if config.CoAuthors != "" {
+		headers = append(headers, "Co-authored Commits")
+	}
+	if config.Dedupe != "" {
+		headers = append(headers, "Collapsed Commits")
+	}
+	if config.BotMode != "" {
+		headers = append(headers, "Bot")
+	}
+	if showTenure(config) {
+		headers = append(headers, "First Commit", "Last Commit", "Active Days", "Tenure Days", "Longest Gap Days")
+	}
+	headers = append(headers, languageHeaders(config.Languages)...)
+	if showWindow(config) {
+		headers = append(headers, "Since", "Until")
+	}
+	return headers
+}
+
+// csvRecord renders a contributor; ownership shares are of surviving lines
+func csvRecord(contributor *types.ContributorStats, config Config, surviving int) []string {
+	record := []string{
+		contributor.Name,
+		contributor.Email,
+		strconv.Itoa(contributor.CommitCount),
+		strconv.Itoa(contributor.LinesAdded),
+		strconv.Itoa(contributor.LinesDeleted),
+		strconv.Itoa(contributor.LinesChanged),
+	}
+	if config.Ownership {
+		record = append(record, strconv.Itoa(contributor.OwnedLines), ownershipShare(contributor.OwnedLines, surviving))
+	}
+	if showBinary(config) {
+		record = append(record, strconv.Itoa(contributor.BinaryFiles))
+	}
+	if config.BinaryBytes {
+		record = append(record, strconv.Itoa(contributor.BinaryBytesAdded), strconv.Itoa(contributor.BinaryBytesDeleted))
+	}
+	if showAliases(config) {
+		record = append(record, strings.Join(contributor.Aliases, "; "))
+	}
+	if config.CoAuthors != "" {
+		record = append(record, strconv.Itoa(contributor.CoAuthoredCommits))
+	}
+	if config.Dedupe != "" {
+		record = append(record, strconv.Itoa(contributor.CollapsedCommits))
+	}
+	if config.BotMode != "" {
+		record = append(record, strconv.FormatBool(contributor.IsBot))
+	}
+	if showTenure(config) {
+		record = append(record,
+			formatTime(contributor.FirstCommit),
+			formatTime(contributor.LastCommit),
+			strconv.Itoa(contributor.ActiveDays),
+			strconv.Itoa(contributor.TenureDays),
+			strconv.Itoa(contributor.LongestGapDays),
+		)
+	}
+	record = append(record, languageRecord(contributor, config.Languages)...)
+	if showWindow(config) {
+		record = append(record, formatTime(config.Since), formatTime(config.Until))
+	}
+	return record
+}
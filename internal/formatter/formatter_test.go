@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"strings"
 	"testing"
+	"time"
 
 	"ganalyzer/pkg/types"
 )
@@ -43,6 +44,129 @@ func TestFormatter_FormatTable(t *testing.T) {
 	}
 }
 
+func TestFormatter_TimeWindowInHeader(t *testing.T) {
+	formatter := NewFormatter()
+	stats := createTestGlobalStats()
+	config := Config{
+		OutputFormat: "table",
+		SortBy:       "commits",
+		Since:        time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Until:        time.Date(2024, time.March, 31, 23, 59, 59, 0, time.UTC),
+	}
+
+	var buf bytes.Buffer
+	if err := formatter.Format(stats, config, &buf); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	expected := "Time window: 2024-01-01T00:00:00Z to 2024-03-31T23:59:59Z"
+	if !strings.Contains(buf.String(), expected) {
+		t.Errorf("Expected %q in header, got:\n%s", expected, buf.String())
+	}
+
+	config.OutputFormat = "json"
+	buf.Reset()
+	if err := formatter.Format(stats, config, &buf); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	var result struct {
+		Window struct {
+			Since time.Time `json:"since"`
+			Until time.Time `json:"until"`
+		} `json:"window"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+	if !result.Window.Since.Equal(config.Since) || !result.Window.Until.Equal(config.Until) {
+		t.Errorf("Unexpected JSON window: %+v", result.Window)
+	}
+}
+
+func TestFormatter_Bots(t *testing.T) {
+	formatter := NewFormatter()
+	stats := createTestGlobalStats()
+	stats.Contributors["dependabot[bot]"] = &types.ContributorStats{Name: "dependabot[bot]", CommitCount: 50, IsBot: true}
+	stats.Contributors["CI"] = &types.ContributorStats{Name: "CI", CommitCount: 40, IsBot: true}
+
+	config := Config{OutputFormat: "table", SortBy: "commits", TopN: 1, BotMode: "separate"}
+	var buf bytes.Buffer
+	if err := formatter.Format(stats, config, &buf); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	output := buf.String()
+	bots := strings.Index(output, "Bots:")
+	if bots < 0 || strings.Index(output, "Alice") > bots {
+		t.Fatalf("Expected humans listed before a separate bots section, got:\n%s", output)
+	}
+	if !strings.Contains(output[bots:], "dependabot[bot] ") || strings.Contains(output, "dependabot[bot] [bot]") {
+		t.Errorf("Expected top bot without a duplicate marker, got:\n%s", output)
+	}
+
+	config = Config{OutputFormat: "csv", SortBy: "commits", BotMode: "flag"}
+	buf.Reset()
+	if err := formatter.Format(stats, config, &buf); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if !strings.HasSuffix(lines[0], ",Bot") || !strings.HasSuffix(lines[1], ",true") || !strings.HasSuffix(lines[3], ",false") {
+		t.Errorf("Expected Bot column marking bot rows, got:\n%s", buf.String())
+	}
+}
+
+func TestFormatter_PerRepository(t *testing.T) {
+	formatter := NewFormatter()
+	stats := createTestGlobalStats()
+	other := types.NewRepository("/path/to/other-repo")
+	other.Contributors["Carol"] = &types.ContributorStats{Name: "Carol", CommitCount: 7}
+	other.Contributors["Bob"] = &types.ContributorStats{Name: "Bob", CommitCount: 1}
+	stats.AddRepository(other)
+
+	config := Config{OutputFormat: "table", SortBy: "commits", TopN: 1, PerRepository: true}
+	var buf bytes.Buffer
+	if err := formatter.Format(stats, config, &buf); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	output := buf.String()
+	section := strings.Index(output, "Repository: other-repo")
+	if strings.Index(output, "Repository: test-repo") < 0 || section < 0 {
+		t.Fatalf("Expected a section per repository, got:\n%s", output)
+	}
+	if !strings.Contains(output[section:], "Carol") || strings.Contains(output[section:], "Bob") {
+		t.Errorf("Expected only the top contributor of other-repo, got:\n%s", output[section:])
+	}
+
+	config.OutputFormat = "json"
+	config.TopN = 0
+	buf.Reset()
+	if err := formatter.Format(stats, config, &buf); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	var result struct {
+		Repositories []struct {
+			Name         string                    `json:"name"`
+			Contributors []*types.ContributorStats `json:"contributors"`
+		} `json:"repositories"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+	if len(result.Repositories) != 2 || len(result.Repositories[1].Contributors) != 2 || result.Repositories[1].Contributors[0].Name != "Carol" {
+		t.Errorf("Expected sorted contributors nested under each repository, got %+v", result.Repositories)
+	}
+
+	config.OutputFormat = "csv"
+	buf.Reset()
+	if err := formatter.Format(stats, config, &buf); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 || !strings.HasPrefix(lines[0], "Repository,Path,Name") || !strings.HasPrefix(lines[3], "other-repo,/path/to/other-repo,Carol,") {
+		t.Errorf("Expected one row per repository and contributor, got:\n%s", buf.String())
+	}
+}
+
 func TestFormatter_FormatJSON(t *testing.T) {
 	formatter := NewFormatter()
 	stats := createTestGlobalStats()
@@ -77,6 +201,336 @@ func TestFormatter_FormatJSON(t *testing.T) {
 	}
 }
 
+func TestFormatter_JSONMetadata(t *testing.T) {
+	formatter := NewFormatter()
+	stats := createTestGlobalStats()
+	stats.Repositories[0].Head = "0123456789abcdef0123456789abcdef01234567"
+	generatedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	config := Config{
+		OutputFormat: "json",
+		SortBy:       "commits",
+		GeneratedAt:  generatedAt,
+		Options:      map[string]string{"sort": "commits"},
+	}
+
+	var buf bytes.Buffer
+	if err := formatter.Format(stats, config, &buf); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	var result struct {
+		SchemaVersion string `json:"schema_version"`
+		Metadata      struct {
+			ToolVersion string            `json:"tool_version"`
+			GeneratedAt time.Time         `json:"generated_at"`
+			Options     map[string]string `json:"options"`
+		} `json:"metadata"`
+		Repositories []struct {
+			Head         string          `json:"head"`
+			Contributors json.RawMessage `json:"contributors"`
+		} `json:"repositories"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+
+	if result.SchemaVersion != SchemaVersion {
+		t.Errorf("Expected schema_version %s, got %q", SchemaVersion, result.SchemaVersion)
+	}
+	if result.Metadata.ToolVersion == "" || !result.Metadata.GeneratedAt.Equal(generatedAt) || result.Metadata.Options["sort"] != "commits" {
+		t.Errorf("Unexpected metadata: %+v", result.Metadata)
+	}
+	if len(result.Repositories) != 1 || result.Repositories[0].Head != stats.Repositories[0].Head {
+		t.Fatalf("Expected the repository HEAD, got %+v", result.Repositories)
+	}
+	if result.Repositories[0].Contributors != nil {
+		t.Errorf("Expected repository contributors only with the per-repository view, got %s", result.Repositories[0].Contributors)
+	}
+}
+
+func TestFormatter_Ownership(t *testing.T) {
+	formatter := NewFormatter()
+	stats := types.NewGlobalStats()
+	repo := types.NewRepository("/path/to/test-repo")
+	repo.Head = "aaa"
+	repo.SurvivingLines = 200
+	repo.Contributors["Alice"] = &types.ContributorStats{Name: "Alice", Email: "alice@example.com", CommitCount: 3, OwnedLines: 150}
+	repo.Contributors["Bob"] = &types.ContributorStats{Name: "Bob", Email: "bob@example.com", CommitCount: 5, OwnedLines: 10}
+	stats.AddRepository(repo)
+
+	var buf bytes.Buffer
+	config := Config{OutputFormat: "table", SortBy: "ownership", Ownership: true}
+	if err := formatter.Format(stats, config, &buf); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	output := buf.String()
+	if !strings.Contains(output, "Owned Lines") || !strings.Contains(output, "75.0%") {
+		t.Errorf("Expected ownership columns, got:\n%s", output)
+	}
+	if strings.Index(output, "Alice") > strings.Index(output, "Bob") {
+		t.Errorf("Expected Alice first when sorting by ownership, got:\n%s", output)
+	}
+
+	buf.Reset()
+	config.OutputFormat = "csv"
+	if err := formatter.Format(stats, config, &buf); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if lines[0] != "Name,Email,Commits,Lines Added,Lines Deleted,Total Lines,Owned Lines,Ownership %" || lines[1] != "Alice,alice@example.com,3,0,0,0,150,75.0" {
+		t.Errorf("Unexpected ownership CSV:\n%s", buf.String())
+	}
+}
+
+func TestFormatter_RepositorySummary(t *testing.T) {
+	stats := types.NewGlobalStats()
+	for _, path := range []string{"/src/api", "/src/web"} {
+		repo := types.NewRepository(path)
+		repo.Contributors["Alice"] = &types.ContributorStats{Name: "Alice", CommitCount: 9}
+		repo.Contributors["Bob"] = &types.ContributorStats{Name: "Bob", CommitCount: 1}
+		repo.AddFileWeight("a.go", "Alice", 5)
+		if repo.Name == "web" {
+			// Every other file is shared, so both must leave to orphan most of them
+			repo.AddFileWeight("b.go", "Bob", 5)
+			for _, path := range []string{"c.go", "d.go"} {
+				repo.AddFileWeight(path, "Alice", 5)
+				repo.AddFileWeight(path, "Bob", 5)
+			}
+		}
+		stats.AddRepository(repo)
+	}
+
+	formatter := NewFormatter()
+	config := Config{OutputFormat: "table", SortBy: "commits", Concentration: true, RiskBusFactor: 1, RiskTopShare: 0.95}
+
+	var buf bytes.Buffer
+	if err := formatter.Format(stats, config, &buf); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	output := buf.String()
+	if !strings.Contains(output, "Repository Summary:") {
+		t.Fatalf("Expected a repository summary, got:\n%s", output)
+	}
+	if !strings.Contains(output, "High risk: api (bus factor 1 <= 1)") || strings.Contains(output, "High risk: web") {
+		t.Errorf("Expected only api to be flagged, got:\n%s", output)
+	}
+
+	buf.Reset()
+	config.OutputFormat = "json"
+	if err := formatter.Format(stats, config, &buf); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	var result struct {
+		Repositories []struct {
+			Name          string `json:"name"`
+			Concentration struct {
+				BusFactor int     `json:"bus_factor"`
+				TopShare  float64 `json:"top_share"`
+				HighRisk  bool    `json:"high_risk"`
+			} `json:"concentration"`
+		} `json:"repositories"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+	web := result.Repositories[1].Concentration
+	if web.BusFactor != 2 || web.TopShare != 0.9 || web.HighRisk {
+		t.Errorf("Unexpected web concentration: %+v", web)
+	}
+}
+
+func TestFormatter_FilteredLines(t *testing.T) {
+	stats := types.NewGlobalStats()
+	for _, path := range []string{"/src/api", "/src/web"} {
+		repo := types.NewRepository(path)
+		repo.Contributors["Alice"] = &types.ContributorStats{Name: "Alice"}
+		filtered := []types.FilteredCredit{{Filter: "package-lock.json", Lines: 1200}}
+		if repo.Name == "web" {
+			filtered = append(filtered, types.FilteredCredit{Filter: "linguist-generated", Lines: 40})
+		}
+		repo.Credit(repo.Name, types.CommitCredit{Key: "Alice", Commits: 1, Filtered: filtered})
+		stats.AddRepository(repo)
+	}
+
+	formatter := NewFormatter()
+	config := Config{OutputFormat: "table", SortBy: "commits"}
+
+	var buf bytes.Buffer
+	if err := formatter.Format(stats, config, &buf); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	output := buf.String()
+	want := "Lines excluded from statistics:\n  - package-lock.json: 2400\n  - linguist-generated: 40\n"
+	if !strings.Contains(output, want) {
+		t.Errorf("Expected filtered lines in the header, got:\n%s", output)
+	}
+
+	buf.Reset()
+	config.OutputFormat = "json"
+	if err := formatter.Format(stats, config, &buf); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	var result struct {
+		FilteredLines map[string]int `json:"filtered_lines"`
+		Repositories  []struct {
+			FilteredLines map[string]int `json:"filtered_lines"`
+		} `json:"repositories"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+	if result.FilteredLines["package-lock.json"] != 2400 || result.FilteredLines["linguist-generated"] != 40 {
+		t.Errorf("Unexpected filtered lines: %v", result.FilteredLines)
+	}
+	if len(result.Repositories) != 2 || len(result.Repositories[0].FilteredLines) != 1 {
+		t.Errorf("Expected filtered lines per repository, got %+v", result.Repositories)
+	}
+}
+
+func TestFormatter_Languages(t *testing.T) {
+	stats := types.NewGlobalStats()
+	repo := types.NewRepository("/src/infra")
+	repo.Contributors["Alice"] = &types.ContributorStats{Name: "Alice", Email: "alice@example.com"}
+	repo.Contributors["Bob"] = &types.ContributorStats{Name: "Bob", Email: "bob@example.com"}
+	repo.Credit("aaa", types.CommitCredit{Key: "Alice", Commits: 1, LinesAdded: 12, LinesDeleted: 2, Languages: []types.LanguageCredit{
+		{Language: "Go", LanguageLines: types.LanguageLines{LinesAdded: 10, LinesDeleted: 2}},
+		{Language: "YAML", LanguageLines: types.LanguageLines{LinesAdded: 2}},
+	}})
+	repo.Credit("bbb", types.CommitCredit{Key: "Bob", Commits: 2, LinesAdded: 30, Languages: []types.LanguageCredit{
+		{Language: "Terraform", LanguageLines: types.LanguageLines{LinesAdded: 30}},
+	}})
+	stats.AddRepository(repo)
+
+	formatter := NewFormatter()
+	config := Config{OutputFormat: "table", SortBy: "commits", Languages: []string{"all"}}
+
+	var buf bytes.Buffer
+	if err := formatter.Format(stats, config, &buf); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	output := buf.String()
+	if !strings.Contains(output, "Terraform       Go     YAML") {
+		t.Errorf("Expected language columns by lines changed, got:\n%s", output)
+	}
+
+	buf.Reset()
+	config.OutputFormat = "csv"
+	config.Languages = []string{"go", "Rust"}
+	if err := formatter.Format(stats, config, &buf); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if !strings.HasSuffix(lines[0], ",Go Lines Added,Go Lines Deleted,Rust Lines Added,Rust Lines Deleted") {
+		t.Errorf("Unexpected CSV header: %s", lines[0])
+	}
+	if lines[2] != "Alice,alice@example.com,1,12,2,14,10,2,0,0" {
+		t.Errorf("Unexpected CSV record: %s", lines[2])
+	}
+
+	buf.Reset()
+	config.OutputFormat = "json"
+	config.Languages = nil
+	if err := formatter.Format(stats, config, &buf); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	var result struct {
+		Contributors []struct {
+			Name      string                         `json:"name"`
+			Languages map[string]types.LanguageLines `json:"languages"`
+		} `json:"contributors"`
+		Repositories []struct {
+			Languages map[string]types.LanguageLines `json:"languages"`
+		} `json:"repositories"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to parse JSON output: %v", err)
+	}
+	if result.Contributors[1].Name != "Alice" || result.Contributors[1].Languages["Go"].LinesDeleted != 2 {
+		t.Errorf("Unexpected contributor languages: %+v", result.Contributors)
+	}
+	if got := result.Repositories[0].Languages; len(got) != 3 || got["Terraform"].LinesAdded != 30 {
+		t.Errorf("Unexpected repository languages: %v", got)
+	}
+}
+
+func TestFormatter_BinaryFiles(t *testing.T) {
+	formatter := NewFormatter()
+	stats := types.NewGlobalStats()
+	repo := types.NewRepository("/path/to/site")
+	repo.Contributors["Dana"] = &types.ContributorStats{Name: "Dana", Email: "dana@example.com", CommitCount: 2, BinaryFiles: 6, BinaryBytesAdded: 3 << 20, BinaryBytesDeleted: 512}
+	repo.Contributors["Bob"] = &types.ContributorStats{Name: "Bob", Email: "bob@example.com", CommitCount: 5}
+	stats.AddRepository(repo)
+
+	var buf bytes.Buffer
+	config := Config{OutputFormat: "table", SortBy: "binary", BinaryBytes: true}
+	if err := formatter.Format(stats, config, &buf); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	output := buf.String()
+	if !strings.Contains(output, "Binary Files") || !strings.Contains(output, "3.0 MiB") || !strings.Contains(output, "512 B") {
+		t.Errorf("Expected binary columns, got:\n%s", output)
+	}
+	if strings.Index(output, "Dana") > strings.Index(output, "Bob") {
+		t.Errorf("Expected Dana first when sorting by binary files, got:\n%s", output)
+	}
+
+	buf.Reset()
+	config = Config{OutputFormat: "csv", SortBy: "commits", Binary: true}
+	if err := formatter.Format(stats, config, &buf); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if lines[0] != "Name,Email,Commits,Lines Added,Lines Deleted,Total Lines,Binary Files" || lines[2] != "Dana,dana@example.com,2,0,0,0,6" {
+		t.Errorf("Unexpected binary CSV:\n%s", buf.String())
+	}
+}
+
+func TestFormatter_Tenure(t *testing.T) {
+	formatter := NewFormatter()
+	stats := types.NewGlobalStats()
+	repo := types.NewRepository("/path/to/api")
+	repo.Contributors["Alice"] = &types.ContributorStats{Name: "Alice", Email: "alice@example.com"}
+	repo.Credit("aaa", types.CommitCredit{Key: "Alice", Commits: 1, Time: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)})
+	repo.Credit("bbb", types.CommitCredit{Key: "Alice", Commits: 1, Time: time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC)})
+	stats.AddRepository(repo)
+
+	var buf bytes.Buffer
+	if err := formatter.Format(stats, Config{OutputFormat: "table", SortBy: "tenure"}, &buf); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	lines := strings.Split(buf.String(), "\n")
+	var header, row string
+	for i, line := range lines {
+		if strings.Contains(line, "Active Days") && i+2 < len(lines) {
+			header, row = line, lines[i+2]
+			break
+		}
+	}
+	if fields := strings.Fields(header); len(fields) < 4 || fields[len(fields)-4] != "Days" || fields[len(fields)-3] != "Tenure" {
+		t.Fatalf("Expected a Tenure column next to Active Days, got:\n%s", buf.String())
+	}
+	if fields := strings.Fields(row); len(fields) < 2 || fields[len(fields)-2] != "10" {
+		t.Errorf("Expected a tenure of 10 days, got row %q", row)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		bytes int
+		want  string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1536, "1.5 KiB"},
+		{5 << 30, "5.0 GiB"},
+	}
+	for _, tt := range tests {
+		if got := formatBytes(tt.bytes); got != tt.want {
+			t.Errorf("formatBytes(%d) = %q, want %q", tt.bytes, got, tt.want)
+		}
+	}
+}
+
 func TestFormatter_FormatCSV(t *testing.T) {
 	formatter := NewFormatter()
 	stats := createTestGlobalStats()
@@ -110,6 +564,17 @@ func TestFormatter_FormatCSV(t *testing.T) {
 	if !strings.Contains(output, "Alice") || !strings.Contains(output, "Bob") {
 		t.Error("Expected contributors not found in CSV output")
 	}
+
+	// A bounded window is recorded on every row; an open end stays empty
+	buf.Reset()
+	config.Since = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := formatter.Format(stats, config, &buf); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	lines = strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if !strings.HasSuffix(lines[0], ",Since,Until") || !strings.HasSuffix(lines[1], ",2024-01-01T00:00:00Z,") {
+		t.Errorf("Expected the time window in every row, got:\n%s", buf.String())
+	}
 }
 
 func TestFormatter_UnsupportedFormat(t *testing.T) {
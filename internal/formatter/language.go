@@ -0,0 +1,82 @@
+package formatter
+
+import (
+	"strconv"
+	"strings"
+
+	"ganalyzer/pkg/types"
+)
+
+// LanguagesAll selects a column for every language seen, most changed first
+const LanguagesAll = "all"
+
+// resolveLanguages turns the requested language columns into the languages
+// reported: LanguagesAll expands to every language of every contributor, and
+// names are matched case-insensitively against the languages seen
+func resolveLanguages(requested []string, stats *types.GlobalStats) []string {
+	if len(requested) == 0 {
+		return nil
+	}
+
+	contributors := make([]*types.ContributorStats, 0, len(stats.Contributors))
+	for _, contributor := range stats.Contributors {
+		contributors = append(contributors, contributor)
+	}
+	seen := types.LanguagesByLines(contributors)
+
+	languages := make([]string, 0, len(requested))
+	added := make(map[string]bool)
+	add := func(language string) {
+		if !added[language] {
+			added[language] = true
+			languages = append(languages, language)
+		}
+	}
+	for _, name := range requested {
+		if strings.EqualFold(name, LanguagesAll) {
+			for _, language := range seen {
+				add(language)
+			}
+			continue
+		}
+		canonical := name
+		for _, language := range seen {
+			if strings.EqualFold(language, name) {
+				canonical = language
+				break
+			}
+		}
+		add(canonical)
+	}
+	return languages
+}
+
+// languageColumns shows the lines each contributor changed in every selected language
+func languageColumns(languages []string) []column {
+	columns := make([]column, 0, len(languages))
+	for _, language := range languages {
+		columns = append(columns, column{language, max(len(language), 8), func(c *types.ContributorStats) string {
+			return strconv.Itoa(c.Languages[language].Changed())
+		}})
+	}
+	return columns
+}
+
+// languageHeaders are the CSV headers of the selected languages
+func languageHeaders(languages []string) []string {
+	headers := make([]string, 0, 2*len(languages))
+	for _, language := range languages {
+		headers = append(headers, language+" Lines Added", language+" Lines Deleted")
+	}
+	return headers
+}
+
+// languageRecord renders a contributor's lines in the selected languages
+func languageRecord(contributor *types.ContributorStats, languages []string) []string {
+	record := make([]string, 0, 2*len(languages))
+	for _, language := range languages {
+		lines := contributor.Languages[language]
+		record = append(record, strconv.Itoa(lines.LinesAdded), strconv.Itoa(lines.LinesDeleted))
+	}
+	return record
+}
@@ -0,0 +1,171 @@
+package formatter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"ganalyzer/pkg/types"
+)
+
+const (
+	// Metrics a matrix cell can hold, selected with Config.MatrixMetric
+	MatrixCommits = "commits"
+	MatrixLines   = "lines"
+	MatrixShare   = "share"
+
+	// Minimum width of a repository column in the matrix table
+	minMatrixColumnWidth = 8
+)
+
+// matrix holds one row per contributor and one column per repository
+type matrix struct {
+	repos []*types.Repository
+	// labels name the repository columns, totals are their commit counts
+	labels       []string
+	totals       []int
+	contributors []*types.ContributorStats
+	// keys are the contributor keys shared by the global and per-repository maps
+	keys   []string
+	metric string
+}
+
+func newMatrix(stats *types.GlobalStats, contributors []*types.ContributorStats, metric string) *matrix {
+	keyOf := make(map[*types.ContributorStats]string, len(stats.Contributors))
+	for key, contributor := range stats.Contributors {
+		keyOf[contributor] = key
+	}
+
+	keys := make([]string, len(contributors))
+	for i, contributor := range contributors {
+		keys[i] = keyOf[contributor]
+	}
+
+	totals := make([]int, len(stats.Repositories))
+	for i, repo := range stats.Repositories {
+		for _, contributor := range repo.Contributors {
+			totals[i] += contributor.CommitCount
+		}
+	}
+
+	if metric == "" {
+		metric = MatrixCommits
+	}
+	return &matrix{
+		repos:        stats.Repositories,
+		labels:       repositoryLabels(stats.Repositories),
+		totals:       totals,
+		contributors: contributors,
+		keys:         keys,
+		metric:       metric,
+	}
+}
+
+// repositoryLabels names repositories by directory name, falling back to the
+// full path for names that occur more than once
+func repositoryLabels(repos []*types.Repository) []string {
+	count := make(map[string]int)
+	for _, repo := range repos {
+		count[repo.Name]++
+	}
+
+	labels := make([]string, len(repos))
+	for i, repo := range repos {
+		labels[i] = repo.Name
+		if count[repo.Name] > 1 {
+			labels[i] = repo.Path
+		}
+	}
+	return labels
+}
+
+// cell returns the metric of a contributor in a repository and whether the
+// contributor has any activity there
+func (m *matrix) cell(row, col int) (string, bool) {
+	stats, ok := m.repos[col].Contributors[m.keys[row]]
+	if !ok {
+		return "", false
+	}
+
+	switch m.metric {
+	case MatrixLines:
+		return strconv.Itoa(stats.LinesChanged), true
+	case MatrixShare:
+		if m.totals[col] == 0 {
+			return "0.0", true
+		}
+		return strconv.FormatFloat(float64(stats.CommitCount)*100/float64(m.totals[col]), 'f', 1, 64), true
+	default:
+		return strconv.Itoa(stats.CommitCount), true
+	}
+}
+
+// formatMatrixTable writes the matrix as an aligned table; empty cells show "-"
+func (f *Formatter) formatMatrixTable(m *matrix, config Config, writer io.Writer) error {
+	if len(m.contributors) == 0 {
+		_, err := fmt.Fprintf(writer, "No contributors found.\n")
+		return err
+	}
+
+	title := fmt.Sprintf("Contributors by repository (%s):", m.metric)
+	if _, err := fmt.Fprintf(writer, "%s\n%s\n\n", title, strings.Repeat("=", len(title))); err != nil {
+		return err
+	}
+
+	nameWidth := f.calculateNameWidth(m.contributors, config)
+	columns := make([]column, len(m.labels))
+	for i, label := range m.labels {
+		columns[i] = column{header: label, width: max(len(label), minMatrixColumnWidth)}
+	}
+
+	if err := f.writeTableHeader(writer, columns, nameWidth); err != nil {
+		return err
+	}
+	for row, contributor := range m.contributors {
+		values := make([]string, len(columns))
+		for col := range columns {
+			value, ok := m.cell(row, col)
+			if !ok {
+				value = "-"
+			} else if m.metric == MatrixShare {
+				value += "%"
+			}
+			values[col] = value
+		}
+		if err := writeTableRow(writer, columns, nameWidth, f.formatContributorName(contributor, config), values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatMatrixCSV writes the matrix with one column per repository; empty cells are 0
+func (f *Formatter) formatMatrixCSV(m *matrix, writer io.Writer) error {
+	csvWriter := csv.NewWriter(writer)
+	defer csvWriter.Flush()
+
+	headers := append([]string{"Name", "Email"}, m.labels...)
+	if err := csvWriter.Write(headers); err != nil {
+		return err
+	}
+
+	for row, contributor := range m.contributors {
+		record := []string{contributor.Name, contributor.Email}
+		for col := range m.repos {
+			value, ok := m.cell(row, col)
+			if !ok {
+				value = "0"
+				if m.metric == MatrixShare {
+					value = "0.0"
+				}
+			}
+			record = append(record, value)
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
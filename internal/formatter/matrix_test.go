@@ -0,0 +1,81 @@
+package formatter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"ganalyzer/pkg/types"
+)
+
+func createMatrixStats() *types.GlobalStats {
+	stats := createTestGlobalStats()
+	other := types.NewRepository("/path/to/other-repo")
+	other.Contributors["Alice"] = &types.ContributorStats{Name: "Alice", CommitCount: 1, LinesChanged: 4}
+	other.Contributors["Carol"] = &types.ContributorStats{Name: "Carol", CommitCount: 3, LinesChanged: 9}
+	stats.AddRepository(other)
+	return stats
+}
+
+func TestFormatter_MatrixCSV(t *testing.T) {
+	tests := []struct {
+		metric   string
+		expected []string
+	}{
+		{MatrixCommits, []string{"Name,Email,test-repo,other-repo", "Alice,alice@example.com,10,1", "Bob,bob@example.com,5,0", "Carol,,0,3"}},
+		{MatrixLines, []string{"Name,Email,test-repo,other-repo", "Alice,alice@example.com,120,4", "Bob,bob@example.com,60,0", "Carol,,0,9"}},
+		{MatrixShare, []string{"Name,Email,test-repo,other-repo", "Alice,alice@example.com,66.7,25.0", "Bob,bob@example.com,33.3,0.0", "Carol,,0.0,75.0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.metric, func(t *testing.T) {
+			var buf bytes.Buffer
+			config := Config{OutputFormat: "matrix-csv", SortBy: "commits", MatrixMetric: tt.metric}
+			if err := NewFormatter().Format(createMatrixStats(), config, &buf); err != nil {
+				t.Fatalf("Format failed: %v", err)
+			}
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			if strings.Join(lines, "|") != strings.Join(tt.expected, "|") {
+				t.Errorf("Expected:\n%s\ngot:\n%s", strings.Join(tt.expected, "\n"), buf.String())
+			}
+		})
+	}
+}
+
+func TestFormatter_MatrixTable(t *testing.T) {
+	var buf bytes.Buffer
+	config := Config{OutputFormat: "matrix", SortBy: "commits", MatrixMetric: MatrixShare}
+	if err := NewFormatter().Format(createMatrixStats(), config, &buf); err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	lines := strings.Split(buf.String(), "\n")
+	var header, carol string
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "Name "):
+			header = line
+		case strings.HasPrefix(line, "Carol "):
+			carol = line
+		}
+	}
+	if !strings.HasSuffix(header, "test-repo other-repo") {
+		t.Errorf("Expected a column per repository, got %q", header)
+	}
+	if len(carol) != len(header) || strings.Join(strings.Fields(carol), " ") != "Carol - 75.0%" {
+		t.Errorf("Expected an aligned row with an empty cell, got %q", carol)
+	}
+}
+
+func TestRepositoryLabels(t *testing.T) {
+	repos := []*types.Repository{
+		types.NewRepository("/a/api"),
+		types.NewRepository("/b/api"),
+		types.NewRepository("/a/web"),
+	}
+	labels := repositoryLabels(repos)
+	if strings.Join(labels, ",") != "/a/api,/b/api,web" {
+		t.Errorf("Expected paths for duplicate names, got %v", labels)
+	}
+}
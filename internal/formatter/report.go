@@ -0,0 +1,135 @@
+package formatter
+
+import (
+	"time"
+
+	"ganalyzer/internal/version"
+	"ganalyzer/pkg/types"
+)
+
+// SchemaVersion is the version of the JSON report layout described by
+// JSONSchema. The major version changes when a field is renamed, removed or
+// changes type; the minor version when fields are added.
+const SchemaVersion = "1.0"
+
+// report is the JSON document written by the json format
+type report struct {
+	SchemaVersion string      `json:"schema_version"`
+	Metadata      metadata    `json:"metadata"`
+	Window        *timeWindow `json:"window,omitempty"`
+	// Repositories carry contributors only with the per-repository view
+	Repositories     []repositoryView `json:"repositories"`
+	SharedHistory    [][]string       `json:"shared_history,omitempty"`
+	UniqueCommits    int              `json:"unique_commits"`
+	DuplicateCommits int              `json:"duplicate_commits"`
+	// SurvivingLines is the total blamed for ownership, present in ownership mode
+	SurvivingLines int `json:"surviving_lines,omitempty"`
+	// FilteredLines counts the changed lines path filters left out, by filter
+	FilteredLines map[string]int            `json:"filtered_lines,omitempty"`
+	Contributors  []*types.ContributorStats `json:"contributors"`
+	Bots          []*types.ContributorStats `json:"bots,omitempty"`
+	// Activity is the long-form activity series, present when an interval is configured
+	Activity []activityRow `json:"activity,omitempty"`
+}
+
+// metadata describes the run that produced a report
+type metadata struct {
+	ToolVersion string    `json:"tool_version"`
+	GeneratedAt time.Time `json:"generated_at"`
+	// Options are the command line options in effect, by flag name
+	Options map[string]string `json:"options"`
+}
+
+// timeWindow is the JSON representation of the analyzed time range
+type timeWindow struct {
+	Since *time.Time `json:"since,omitempty"`
+	Until *time.Time `json:"until,omitempty"`
+}
+
+// repositoryView is the JSON form of a repository with its ranked contributors
+type repositoryView struct {
+	Name        string              `json:"name"`
+	Path        string              `json:"path"`
+	Head        string              `json:"head,omitempty"`
+	Revision    string              `json:"revision,omitempty"`
+	Refs        []types.ResolvedRef `json:"refs,omitempty"`
+	RootCommits []string            `json:"root_commits,omitempty"`
+	// SurvivingLines is the repository's total blamed for ownership
+	SurvivingLines int            `json:"surviving_lines,omitempty"`
+	FilteredLines  map[string]int `json:"filtered_lines,omitempty"`
+	// Languages sums the lines the repository's contributors changed per language
+	Languages     map[string]types.LanguageLines `json:"languages,omitempty"`
+	Concentration *concentrationView             `json:"concentration,omitempty"`
+	Contributors  []*types.ContributorStats      `json:"contributors,omitempty"`
+}
+
+func newReport(contributors, bots []*types.ContributorStats, stats *types.GlobalStats, config Config) report {
+	generatedAt := config.GeneratedAt
+	if generatedAt.IsZero() {
+		generatedAt = time.Now()
+	}
+	options := config.Options
+	if options == nil {
+		options = make(map[string]string)
+	}
+
+	return report{
+		SchemaVersion: SchemaVersion,
+		Metadata: metadata{
+			ToolVersion: version.Short(),
+			GeneratedAt: generatedAt,
+			Options:     options,
+		},
+		Window:           newTimeWindow(config),
+		Repositories:     repositoryViews(stats.Repositories, config),
+		SharedHistory:    sharedHistoryPaths(stats),
+		UniqueCommits:    stats.UniqueCommits(),
+		DuplicateCommits: stats.DuplicateCommits,
+		SurvivingLines:   stats.SurvivingLines(),
+		FilteredLines:    stats.FilteredLines(),
+		Contributors:     contributors,
+		Bots:             bots,
+		Activity:         activityRows(stats.Repositories, config),
+	}
+}
+
+// repositoryViews describes every repository, adding sorted and limited
+// contributor lists when the per-repository view is requested
+func repositoryViews(repos []*types.Repository, config Config) []repositoryView {
+	views := make([]repositoryView, 0, len(repos))
+	for _, repo := range repos {
+		view := repositoryView{
+			Name:           repo.Name,
+			Path:           repo.Path,
+			Head:           repo.Head,
+			Revision:       repo.Revision,
+			Refs:           repo.Refs,
+			RootCommits:    repo.RootCommits,
+			SurvivingLines: repo.SurvivingLines,
+			FilteredLines:  repo.FilteredLines,
+			Languages:      repo.Languages(),
+			Concentration:  newConcentrationView(repo, config),
+		}
+		if config.PerRepository {
+			view.Contributors = repo.GetSortedContributors(config.SortBy, config.TopN)
+		}
+		views = append(views, view)
+	}
+	return views
+}
+
+func newTimeWindow(config Config) *timeWindow {
+	if config.Since.IsZero() && config.Until.IsZero() {
+		return nil
+	}
+	window := &timeWindow{}
+	if !config.Since.IsZero() {
+		since := config.Since
+		window.Since = &since
+	}
+	if !config.Until.IsZero() {
+		until := config.Until
+		window.Until = &until
+	}
+	return window
+}
@@ -0,0 +1,67 @@
+package formatter
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestJSONSchema_MatchesCommittedSchema(t *testing.T) {
+	schema, err := JSONSchema()
+	if err != nil {
+		t.Fatalf("JSONSchema failed: %v", err)
+	}
+
+	committed, err := os.ReadFile("../../schema/report.schema.json")
+	if err != nil {
+		t.Fatalf("Failed to read committed schema: %v", err)
+	}
+	if !bytes.Equal(schema, committed) {
+		t.Errorf("schema/report.schema.json is out of date; regenerate it with `make schema`")
+	}
+}
+
+func TestJSONSchema_DescribesReport(t *testing.T) {
+	data, err := JSONSchema()
+	if err != nil {
+		t.Fatalf("JSONSchema failed: %v", err)
+	}
+
+	var schema struct {
+		Required   []string `json:"required"`
+		Properties map[string]struct {
+			Type       string                     `json:"type"`
+			Format     string                     `json:"format"`
+			Required   []string                   `json:"required"`
+			Properties map[string]json.RawMessage `json:"properties"`
+			Items      struct {
+				Properties map[string]json.RawMessage `json:"properties"`
+			} `json:"items"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("Failed to parse schema: %v", err)
+	}
+
+	for _, field := range []string{"schema_version", "metadata", "repositories", "contributors"} {
+		if !slices.Contains(schema.Required, field) {
+			t.Errorf("Expected %s to be required, got %v", field, schema.Required)
+		}
+	}
+	if slices.Contains(schema.Required, "bots") {
+		t.Errorf("Expected optional bots, got required %v", schema.Required)
+	}
+
+	metadata := schema.Properties["metadata"]
+	if _, ok := metadata.Properties["generated_at"]; !ok || !slices.Contains(metadata.Required, "tool_version") {
+		t.Errorf("Unexpected metadata schema: %+v", metadata)
+	}
+	if _, ok := schema.Properties["contributors"].Items.Properties["lines_changed"]; !ok {
+		t.Errorf("Expected contributor properties to use the JSON field names")
+	}
+	if _, ok := schema.Properties["repositories"].Items.Properties["head"]; !ok {
+		t.Errorf("Expected repositories to describe the HEAD commit")
+	}
+}
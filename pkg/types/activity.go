@@ -0,0 +1,145 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	// Granularities of activity time series
+	IntervalWeek    = "week"
+	IntervalMonth   = "month"
+	IntervalQuarter = "quarter"
+)
+
+// Activity is what a contributor did within one time bucket
+type Activity struct {
+	Commits int
+	// CoAuthored counts commits the contributor co-authored without being
+	// credited with them, as under the split and column policies
+	CoAuthored   int
+	LinesAdded   int
+	LinesDeleted int
+}
+
+// Bucket is one time bucket of a series and the activity within it
+type Bucket struct {
+	Start time.Time
+	Activity
+}
+
+// BucketStart returns the start of the bucket containing t in UTC: the Monday
+// of its ISO week, or the first day of its month or quarter
+func BucketStart(t time.Time, interval string) time.Time {
+	t = t.UTC()
+	year, month, day := t.Date()
+	switch interval {
+	case IntervalWeek:
+		// Monday is the first day of an ISO week
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(year, month, day-offset, 0, 0, 0, 0, time.UTC)
+	case IntervalQuarter:
+		return time.Date(year, month-(month-1)%3, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// NextBucket returns the start of the bucket following the one starting at start
+func NextBucket(start time.Time, interval string) time.Time {
+	switch interval {
+	case IntervalWeek:
+		return start.AddDate(0, 0, 7)
+	case IntervalQuarter:
+		return start.AddDate(0, 3, 0)
+	default:
+		return start.AddDate(0, 1, 0)
+	}
+}
+
+// BucketLabel names the bucket starting at start: "2024-W05", "2024-01" or "2024-Q1"
+func BucketLabel(start time.Time, interval string) string {
+	switch interval {
+	case IntervalWeek:
+		year, week := start.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case IntervalQuarter:
+		return fmt.Sprintf("%d-Q%d", start.Year(), (int(start.Month())-1)/3+1)
+	default:
+		return start.Format("2006-01")
+	}
+}
+
+// addActivity adds a commit credit to the bucket it falls in, or removes it
+// when sign is -1; buckets left without any activity are dropped
+func (cs *ContributorStats) addActivity(credit CommitCredit, sign int) {
+	if credit.Bucket.IsZero() {
+		return
+	}
+	if cs.Activity == nil {
+		cs.Activity = make(map[time.Time]*Activity)
+	}
+	activity, ok := cs.Activity[credit.Bucket]
+	if !ok {
+		activity = &Activity{}
+		cs.Activity[credit.Bucket] = activity
+	}
+	change := Activity{Commits: credit.Commits, LinesAdded: credit.LinesAdded, LinesDeleted: credit.LinesDeleted}
+	if credit.Commits == 0 {
+		change.CoAuthored = credit.CoAuthored
+	}
+	activity.add(change, sign)
+	if *activity == (Activity{}) {
+		delete(cs.Activity, credit.Bucket)
+	}
+}
+
+func (a *Activity) add(other Activity, sign int) {
+	a.Commits += sign * other.Commits
+	a.CoAuthored += sign * other.CoAuthored
+	a.LinesAdded += sign * other.LinesAdded
+	a.LinesDeleted += sign * other.LinesDeleted
+}
+
+// Participated returns the commits the contributor authored or co-authored
+func (a Activity) Participated() int {
+	return a.Commits + a.CoAuthored
+}
+
+// Series returns the contributor's non-empty buckets in chronological order
+func (cs *ContributorStats) Series() []Bucket {
+	return series(cs.Activity)
+}
+
+// Activity returns the work of all the repository's contributors per bucket
+func (r *Repository) Activity() map[time.Time]*Activity {
+	totals := make(map[time.Time]*Activity)
+	for _, stats := range r.Contributors {
+		mergeActivity(totals, stats.Activity)
+	}
+	return totals
+}
+
+func series(activity map[time.Time]*Activity) []Bucket {
+	buckets := make([]Bucket, 0, len(activity))
+	for start, a := range activity {
+		buckets = append(buckets, Bucket{Start: start, Activity: *a})
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].Start.Before(buckets[j].Start)
+	})
+	return buckets
+}
+
+// mergeActivity adds every bucket of source to target
+func mergeActivity(target, source map[time.Time]*Activity) {
+	for start, a := range source {
+		existing, ok := target[start]
+		if !ok {
+			existing = &Activity{}
+			target[start] = existing
+		}
+		existing.add(*a, 1)
+	}
+}
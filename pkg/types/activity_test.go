@@ -0,0 +1,73 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuckets(t *testing.T) {
+	// A Sunday in the last ISO week of 2020, which started on Monday 2020-12-28
+	date := time.Date(2021, 1, 3, 18, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		interval  string
+		wantStart time.Time
+		wantNext  time.Time
+		wantLabel string
+	}{
+		{IntervalWeek, time.Date(2020, 12, 28, 0, 0, 0, 0, time.UTC), time.Date(2021, 1, 4, 0, 0, 0, 0, time.UTC), "2020-W53"},
+		{IntervalMonth, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC), "2021-01"},
+		{IntervalQuarter, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 4, 1, 0, 0, 0, 0, time.UTC), "2021-Q1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.interval, func(t *testing.T) {
+			start := BucketStart(date, tt.interval)
+			if !start.Equal(tt.wantStart) {
+				t.Errorf("BucketStart() = %v, want %v", start, tt.wantStart)
+			}
+			if next := NextBucket(start, tt.interval); !next.Equal(tt.wantNext) {
+				t.Errorf("NextBucket() = %v, want %v", next, tt.wantNext)
+			}
+			if label := BucketLabel(start, tt.interval); label != tt.wantLabel {
+				t.Errorf("BucketLabel() = %q, want %q", label, tt.wantLabel)
+			}
+		})
+	}
+
+	if start := BucketStart(time.Date(2024, 11, 20, 0, 0, 0, 0, time.UTC), IntervalQuarter); start.Month() != time.October {
+		t.Errorf("Expected Q4 to start in October, got %v", start)
+	}
+}
+
+func TestActivity_SharedAndCollapsedCommits(t *testing.T) {
+	jan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	newRepo := func(path string) *Repository {
+		repo := NewRepository(path)
+		repo.Contributors["Alice"] = &ContributorStats{Name: "Alice"}
+		repo.Credit("aaa", CommitCredit{Key: "Alice", Commits: 1, LinesAdded: 5, Bucket: jan})
+		repo.Credit("bbb", CommitCredit{Key: "Alice", Commits: 1, LinesAdded: 2, Bucket: feb})
+		return repo
+	}
+
+	upstream := newRepo("/src/upstream")
+	upstream.Collapse("bbb")
+	if series := upstream.Contributors["Alice"].Series(); len(series) != 1 || !series[0].Start.Equal(jan) {
+		t.Errorf("Expected the collapsed commit to leave its bucket, got %+v", series)
+	}
+
+	fork := newRepo("/src/fork")
+	gs := NewGlobalStats()
+	gs.AddRepository(newRepo("/src/upstream"))
+	gs.AddRepository(fork)
+
+	series := gs.Contributors["Alice"].Series()
+	if len(series) != 2 || series[0].Commits != 1 || series[1].LinesAdded != 2 {
+		t.Errorf("Expected shared commits counted once per bucket, got %+v", series)
+	}
+	if activity := fork.Activity(); activity[jan].Commits != 1 {
+		t.Errorf("Expected the fork to keep its own activity, got %+v", activity[jan])
+	}
+}
@@ -0,0 +1,164 @@
+package types
+
+import (
+	"sort"
+)
+
+const (
+	// Bases of the concentration measures
+	BasisOwnership = "ownership"
+	BasisCommits   = "commits"
+
+	// fileAuthorShare is the weight relative to a file's main author from
+	// which a contributor counts as knowing the file
+	fileAuthorShare = 0.5
+)
+
+// Concentration describes how a repository's knowledge is spread across contributors
+type Concentration struct {
+	// Basis is what the measures were computed from: BasisOwnership (lines
+	// at HEAD) or BasisCommits (history)
+	Basis string `json:"basis"`
+	// Files is the number of files at HEAD with known authors
+	Files int `json:"files"`
+	// BusFactor is the minimum number of contributors whose departure would
+	// leave more than half of the files without an author; 0 when that is
+	// already the case
+	BusFactor int `json:"bus_factor"`
+	// Gini is the inequality of contributions, from 0 (equal) to 1
+	Gini float64 `json:"gini"`
+	// TopShare is the share of the largest contributor, named by TopContributor
+	TopShare       float64 `json:"top_share"`
+	TopContributor string  `json:"top_contributor,omitempty"`
+}
+
+// AddFileWeight records how much a contributor wrote of a file, such as
+// blamed or changed lines
+func (r *Repository) AddFileWeight(path, key string, weight int) {
+	if weight <= 0 {
+		return
+	}
+	if r.Files == nil {
+		r.Files = make(map[string]map[string]int)
+	}
+	if r.Files[path] == nil {
+		r.Files[path] = make(map[string]int)
+	}
+	r.Files[path][key] += weight
+}
+
+// Concentration computes the bus factor from the file weights and the
+// inequality measures from owned lines when blamed, otherwise from commits.
+// Only contributors still in the repository count, so files written solely
+// by removed contributors, such as inactive ones, are orphaned from the start.
+func (r *Repository) Concentration() Concentration {
+	c := Concentration{Basis: BasisCommits, Files: len(r.Files), BusFactor: r.busFactor()}
+	if r.SurvivingLines > 0 {
+		c.Basis = BasisOwnership
+	}
+
+	values := make([]int, 0, len(r.Contributors))
+	total, top := 0, 0
+	for _, stats := range r.Contributors {
+		value := stats.CommitCount
+		if c.Basis == BasisOwnership {
+			value = stats.OwnedLines
+		}
+		values = append(values, value)
+		total += value
+		if value > top || (value == top && value > 0 && stats.Name < c.TopContributor) {
+			top, c.TopContributor = value, stats.Name
+		}
+	}
+	if total > 0 {
+		c.TopShare = float64(top) / float64(total)
+	}
+	c.Gini = gini(values)
+	return c
+}
+
+// busFactor removes the contributor knowing the most files that still have an
+// author until more than half of the files have none left
+func (r *Repository) busFactor() int {
+	authors := make([]map[string]bool, 0, len(r.Files))
+	for _, weights := range r.Files {
+		authors = append(authors, r.fileAuthors(weights))
+	}
+
+	orphaned := func() int {
+		count := 0
+		for _, fileAuthors := range authors {
+			if len(fileAuthors) == 0 {
+				count++
+			}
+		}
+		return count
+	}
+
+	removed := 0
+	for orphaned()*2 <= len(authors) {
+		knows := make(map[string]int)
+		for _, fileAuthors := range authors {
+			for key := range fileAuthors {
+				knows[key]++
+			}
+		}
+		if len(knows) == 0 {
+			break
+		}
+
+		keys := make([]string, 0, len(knows))
+		for key := range knows {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if knows[keys[i]] != knows[keys[j]] {
+				return knows[keys[i]] > knows[keys[j]]
+			}
+			return keys[i] < keys[j]
+		})
+
+		for _, fileAuthors := range authors {
+			delete(fileAuthors, keys[0])
+		}
+		removed++
+	}
+	return removed
+}
+
+// fileAuthors returns the current contributors holding at least
+// fileAuthorShare of the weight of the file's main author
+func (r *Repository) fileAuthors(weights map[string]int) map[string]bool {
+	top := 0
+	for _, weight := range weights {
+		top = max(top, weight)
+	}
+
+	authors := make(map[string]bool)
+	for key, weight := range weights {
+		if _, present := r.Contributors[key]; present && float64(weight) >= fileAuthorShare*float64(top) {
+			authors[key] = true
+		}
+	}
+	return authors
+}
+
+// gini returns the Gini coefficient of non-negative values
+func gini(values []int) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+
+	total, weighted := 0, 0
+	for i, value := range sorted {
+		total += value
+		weighted += (i + 1) * value
+	}
+	if total == 0 {
+		return 0
+	}
+	n := float64(len(sorted))
+	return 2*float64(weighted)/(n*float64(total)) - (n+1)/n
+}
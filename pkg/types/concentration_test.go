@@ -0,0 +1,113 @@
+package types
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRepository_Concentration(t *testing.T) {
+	tests := []struct {
+		name          string
+		files         map[string]map[string]int
+		commits       map[string]int
+		removed       []string
+		wantBusFactor int
+	}{
+		{
+			name: "one author knows most files",
+			files: map[string]map[string]int{
+				"a.go": {"Alice": 10},
+				"b.go": {"Alice": 10, "Bob": 2},
+				"c.go": {"Alice": 10},
+				"d.go": {"Bob": 10},
+			},
+			commits:       map[string]int{"Alice": 8, "Bob": 2},
+			wantBusFactor: 1,
+		},
+		{
+			name: "shared files need both authors to leave",
+			files: map[string]map[string]int{
+				"a.go": {"Alice": 10},
+				"b.go": {"Alice": 10},
+				"c.go": {"Alice": 10, "Bob": 8},
+				"d.go": {"Bob": 10},
+			},
+			commits:       map[string]int{"Alice": 5, "Bob": 5},
+			wantBusFactor: 2,
+		},
+		{
+			name: "files of removed contributors are already orphaned",
+			files: map[string]map[string]int{
+				"a.go": {"Carol": 10},
+				"b.go": {"Carol": 10},
+				"c.go": {"Carol": 10, "Bob": 1},
+				"d.go": {"Bob": 10},
+			},
+			commits:       map[string]int{"Carol": 9, "Bob": 1},
+			removed:       []string{"Carol"},
+			wantBusFactor: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewRepository("/src/api")
+			for key, commits := range tt.commits {
+				repo.Contributors[key] = &ContributorStats{Name: key, CommitCount: commits}
+			}
+			for path, weights := range tt.files {
+				for key, weight := range weights {
+					repo.AddFileWeight(path, key, weight)
+				}
+			}
+			for _, key := range tt.removed {
+				delete(repo.Contributors, key)
+			}
+
+			c := repo.Concentration()
+			if c.BusFactor != tt.wantBusFactor {
+				t.Errorf("BusFactor = %d, want %d", c.BusFactor, tt.wantBusFactor)
+			}
+			if c.Files != len(tt.files) || c.Basis != BasisCommits {
+				t.Errorf("Unexpected files or basis: %+v", c)
+			}
+		})
+	}
+}
+
+func TestRepository_ConcentrationMeasures(t *testing.T) {
+	repo := NewRepository("/src/api")
+	repo.Contributors["Alice"] = &ContributorStats{Name: "Alice", CommitCount: 1, OwnedLines: 30}
+	repo.Contributors["Bob"] = &ContributorStats{Name: "Bob", CommitCount: 1, OwnedLines: 0}
+	repo.Contributors["Carol"] = &ContributorStats{Name: "Carol", CommitCount: 1, OwnedLines: 0}
+
+	c := repo.Concentration()
+	if c.Gini != 0 || math.Abs(c.TopShare-1.0/3) > 1e-9 {
+		t.Errorf("Expected equal commits, got gini %g and top share %g", c.Gini, c.TopShare)
+	}
+
+	repo.SurvivingLines = 30
+	c = repo.Concentration()
+	if c.Basis != BasisOwnership || c.TopShare != 1 || c.TopContributor != "Alice" {
+		t.Errorf("Expected Alice to own everything, got %+v", c)
+	}
+	if math.Abs(c.Gini-2.0/3) > 1e-9 {
+		t.Errorf("Expected gini 2/3, got %g", c.Gini)
+	}
+}
+
+func TestGlobalStats_MergeContributorsMovesFileWeights(t *testing.T) {
+	repo := NewRepository("/src/api")
+	repo.Contributors["Alice"] = &ContributorStats{Name: "Alice"}
+	repo.Contributors["alice"] = &ContributorStats{Name: "alice"}
+	repo.AddFileWeight("a.go", "Alice", 3)
+	repo.AddFileWeight("a.go", "alice", 4)
+
+	gs := NewGlobalStats()
+	gs.AddRepository(repo)
+	gs.MergeContributors("Alice", "alice")
+
+	if weights := repo.Files["a.go"]; len(weights) != 1 || weights["Alice"] != 7 {
+		t.Errorf("Expected merged file weights, got %v", weights)
+	}
+}
@@ -0,0 +1,95 @@
+package types
+
+import "sort"
+
+// LanguageLines is the lines added and deleted in files of one language
+type LanguageLines struct {
+	LinesAdded   int `json:"lines_added"`
+	LinesDeleted int `json:"lines_deleted"`
+}
+
+// Changed returns the lines added and deleted together
+func (l LanguageLines) Changed() int {
+	return l.LinesAdded + l.LinesDeleted
+}
+
+// LanguageCredit is what a commit changed in files of one language. Commit
+// credits list them in a slice rather than a map, since every analyzed commit
+// keeps its credits until the repository is merged.
+type LanguageCredit struct {
+	Language string
+	LanguageLines
+}
+
+// addLanguages adds the per-language lines of a commit credit, or removes
+// them when sign is -1; languages left without changes are dropped
+func (cs *ContributorStats) addLanguages(languages []LanguageCredit, sign int) {
+	if len(languages) == 0 {
+		return
+	}
+	if cs.Languages == nil {
+		cs.Languages = make(map[string]LanguageLines)
+	}
+	for _, credit := range languages {
+		addLanguage(cs.Languages, credit.Language, credit.LanguageLines, sign)
+	}
+}
+
+// mergeLanguages adds other's lines per language to cs
+func (cs *ContributorStats) mergeLanguages(other map[string]LanguageLines) {
+	if len(other) == 0 {
+		return
+	}
+	if cs.Languages == nil {
+		cs.Languages = make(map[string]LanguageLines, len(other))
+	}
+	addLanguageLines(cs.Languages, other, 1)
+}
+
+// Languages returns the lines all the repository's contributors changed per
+// language. Co-authors credited in full count once each, as in their own totals.
+func (r *Repository) Languages() map[string]LanguageLines {
+	totals := make(map[string]LanguageLines)
+	for _, stats := range r.Contributors {
+		addLanguageLines(totals, stats.Languages, 1)
+	}
+	return totals
+}
+
+// LanguagesByLines returns the languages of every contributor ordered by the
+// lines changed in them, most first, with ties in name order
+func LanguagesByLines(contributors []*ContributorStats) []string {
+	totals := make(map[string]LanguageLines)
+	for _, stats := range contributors {
+		addLanguageLines(totals, stats.Languages, 1)
+	}
+
+	languages := make([]string, 0, len(totals))
+	for language := range totals {
+		languages = append(languages, language)
+	}
+	sort.Slice(languages, func(i, j int) bool {
+		if totals[languages[i]].Changed() != totals[languages[j]].Changed() {
+			return totals[languages[i]].Changed() > totals[languages[j]].Changed()
+		}
+		return languages[i] < languages[j]
+	})
+	return languages
+}
+
+func addLanguageLines(target, source map[string]LanguageLines, sign int) {
+	for language, lines := range source {
+		addLanguage(target, language, lines, sign)
+	}
+}
+
+func addLanguage(target map[string]LanguageLines, language string, lines LanguageLines, sign int) {
+	total := target[language]
+	total.LinesAdded += sign * lines.LinesAdded
+	total.LinesDeleted += sign * lines.LinesDeleted
+	if total == (LanguageLines{}) {
+		delete(target, language)
+		return
+	}
+	target[language] = total
+}
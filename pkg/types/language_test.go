@@ -0,0 +1,72 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestContributorStats_Languages(t *testing.T) {
+	goLines := func(added, deleted int) []LanguageCredit {
+		return []LanguageCredit{{Language: "Go", LanguageLines: LanguageLines{LinesAdded: added, LinesDeleted: deleted}}}
+	}
+
+	api := NewRepository("/src/api")
+	api.Contributors["Alice"] = &ContributorStats{Name: "Alice"}
+	api.Contributors["Bob"] = &ContributorStats{Name: "Bob"}
+	api.Credit("aaa", CommitCredit{Key: "Alice", Commits: 1, LinesAdded: 15, Languages: []LanguageCredit{
+		{Language: "Go", LanguageLines: LanguageLines{LinesAdded: 10}},
+		{Language: "YAML", LanguageLines: LanguageLines{LinesAdded: 5}},
+	}})
+	api.Credit("bbb", CommitCredit{Key: "Bob", Commits: 1, LinesAdded: 4, LinesDeleted: 2, Languages: goLines(4, 2)})
+	api.Credit("ccc", CommitCredit{Key: "Alice", Commits: 1, LinesAdded: 3, Languages: []LanguageCredit{{Language: "Terraform", LanguageLines: LanguageLines{LinesAdded: 3}}}})
+
+	want := map[string]LanguageLines{"Go": {LinesAdded: 14, LinesDeleted: 2}, "YAML": {LinesAdded: 5}, "Terraform": {LinesAdded: 3}}
+	if got := api.Languages(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected repository languages %v, got %v", want, got)
+	}
+
+	// A collapsed cherry-pick leaves no empty language behind
+	api.Collapse("ccc")
+	if _, ok := api.Contributors["Alice"].Languages["Terraform"]; ok {
+		t.Errorf("Expected the collapsed commit's language to be dropped, got %v", api.Contributors["Alice"].Languages)
+	}
+
+	// A clone shares commit aaa, which counts once globally
+	clone := NewRepository("/src/api-clone")
+	clone.Contributors["Alice"] = &ContributorStats{Name: "Alice"}
+	clone.Credit("aaa", CommitCredit{Key: "Alice", Commits: 1, LinesAdded: 15, Languages: []LanguageCredit{
+		{Language: "Go", LanguageLines: LanguageLines{LinesAdded: 10}},
+		{Language: "YAML", LanguageLines: LanguageLines{LinesAdded: 5}},
+	}})
+	clone.Credit("ddd", CommitCredit{Key: "Alice", Commits: 1, LinesAdded: 1, Languages: goLines(1, 0)})
+
+	gs := NewGlobalStats()
+	gs.AddRepository(api)
+	gs.AddRepository(clone)
+	alice := gs.Contributors["Alice"]
+	if want := (map[string]LanguageLines{"Go": {LinesAdded: 11}, "YAML": {LinesAdded: 5}}); !reflect.DeepEqual(alice.Languages, want) {
+		t.Errorf("Expected global languages %v, got %v", want, alice.Languages)
+	}
+	if api.Contributors["Alice"].Languages["Go"].LinesAdded != 10 {
+		t.Errorf("Expected the repository's own languages to be untouched, got %v", api.Contributors["Alice"].Languages)
+	}
+
+	gs.MergeContributors("Alice", "Bob")
+	if alice.Languages["Go"] != (LanguageLines{LinesAdded: 15, LinesDeleted: 2}) {
+		t.Errorf("Expected merged Go lines, got %v", alice.Languages)
+	}
+
+	if got := LanguagesByLines([]*ContributorStats{alice}); !reflect.DeepEqual(got, []string{"Go", "YAML"}) {
+		t.Errorf("Expected languages by lines changed, got %v", got)
+	}
+}
+
+func TestLanguagesByLines_Ties(t *testing.T) {
+	contributors := []*ContributorStats{
+		{Languages: map[string]LanguageLines{"YAML": {LinesAdded: 2}, "Go": {LinesDeleted: 2}}},
+		{Languages: map[string]LanguageLines{"Shell": {LinesAdded: 1}, "Go": {LinesAdded: 1}}},
+	}
+	if got := LanguagesByLines(contributors); !reflect.DeepEqual(got, []string{"Go", "YAML", "Shell"}) {
+		t.Errorf("Unexpected language order: %v", got)
+	}
+}
@@ -0,0 +1,150 @@
+package types
+
+import (
+	"sort"
+	"time"
+)
+
+// secondsPerDay converts Unix seconds to UTC day numbers
+const secondsPerDay = 24 * 60 * 60
+
+// addCommitTime records the author time of a credited commit, or forgets it
+// when sign is -1, and keeps the tenure fields up to date
+func (cs *ContributorStats) addCommitTime(at time.Time, sign int) {
+	if at.IsZero() {
+		return
+	}
+	if cs.commitTimes == nil {
+		cs.commitTimes = make(map[int64]int)
+		cs.activeDays = make(map[int64]int)
+	}
+
+	seconds := at.Unix()
+	day := utcDay(seconds)
+	cs.commitTimes[seconds] += sign
+	cs.activeDays[day] += sign
+	daysChanged := cs.activeDays[day] == sign
+	if cs.commitTimes[seconds] <= 0 {
+		delete(cs.commitTimes, seconds)
+	}
+	if cs.activeDays[day] <= 0 {
+		delete(cs.activeDays, day)
+		daysChanged = true
+	}
+
+	if sign > 0 && (cs.FirstCommit.IsZero() || at.Before(cs.FirstCommit)) {
+		cs.FirstCommit = at.UTC()
+	}
+	if sign > 0 && at.After(cs.LastCommit) {
+		cs.LastCommit = at.UTC()
+	}
+	if sign < 0 && (at.Equal(cs.FirstCommit) || at.Equal(cs.LastCommit)) {
+		cs.FirstCommit, cs.LastCommit = commitRange(cs.commitTimes)
+	}
+	if daysChanged {
+		cs.updateActiveDays()
+	}
+}
+
+// mergeCommitTimes adds other's commit times to cs
+func (cs *ContributorStats) mergeCommitTimes(other *ContributorStats) {
+	if len(other.commitTimes) == 0 {
+		return
+	}
+	if cs.commitTimes == nil {
+		cs.commitTimes = make(map[int64]int)
+		cs.activeDays = make(map[int64]int)
+	}
+	for seconds, count := range other.commitTimes {
+		cs.commitTimes[seconds] += count
+	}
+	for day, count := range other.activeDays {
+		cs.activeDays[day] += count
+	}
+	cs.FirstCommit, cs.LastCommit = commitRange(cs.commitTimes)
+	cs.updateActiveDays()
+}
+
+// cloneCommitTimes returns copies of the commit time sets for Clone
+func (cs *ContributorStats) cloneCommitTimes() (map[int64]int, map[int64]int) {
+	if cs.commitTimes == nil {
+		return nil, nil
+	}
+	times := make(map[int64]int, len(cs.commitTimes))
+	for seconds, count := range cs.commitTimes {
+		times[seconds] = count
+	}
+	days := make(map[int64]int, len(cs.activeDays))
+	for day, count := range cs.activeDays {
+		days[day] = count
+	}
+	return times, days
+}
+
+// updateActiveDays recomputes the day based metrics after the set of active days changed
+func (cs *ContributorStats) updateActiveDays() {
+	days := make([]int64, 0, len(cs.activeDays))
+	for day := range cs.activeDays {
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i] < days[j] })
+
+	cs.ActiveDays = len(days)
+	cs.LongestGapDays = 0
+	cs.TenureDays = 0
+	for i := 1; i < len(days); i++ {
+		cs.LongestGapDays = max(cs.LongestGapDays, int(days[i]-days[i-1]-1))
+	}
+	if len(days) > 0 {
+		cs.TenureDays = int(days[len(days)-1]-days[0]) + 1
+	}
+}
+
+// commitRange returns the earliest and latest of the recorded commit times
+func commitRange(times map[int64]int) (time.Time, time.Time) {
+	if len(times) == 0 {
+		return time.Time{}, time.Time{}
+	}
+	first, last := int64(0), int64(0)
+	initialized := false
+	for seconds := range times {
+		if !initialized || seconds < first {
+			first = seconds
+		}
+		if !initialized || seconds > last {
+			last = seconds
+		}
+		initialized = true
+	}
+	return time.Unix(first, 0).UTC(), time.Unix(last, 0).UTC()
+}
+
+func utcDay(seconds int64) int64 {
+	day := seconds / secondsPerDay
+	if seconds < 0 && seconds%secondsPerDay != 0 {
+		day--
+	}
+	return day
+}
+
+// RemoveInactive drops contributors whose last commit is before cutoff, both
+// globally and within every repository, and returns how many were removed
+// globally. A contributor is judged by its own last commit in each scope, so
+// someone still active elsewhere can disappear from a single repository.
+func (gs *GlobalStats) RemoveInactive(cutoff time.Time) int {
+	removed := 0
+	for key, stats := range gs.Contributors {
+		if stats.LastCommit.Before(cutoff) {
+			delete(gs.Contributors, key)
+			removed++
+		}
+	}
+	for _, repo := range gs.Repositories {
+		for key, stats := range repo.Contributors {
+			if stats.LastCommit.Before(cutoff) {
+				delete(repo.Contributors, key)
+			}
+		}
+	}
+	return removed
+}
@@ -0,0 +1,107 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestContributorStats_Tenure(t *testing.T) {
+	day := func(d, hour int) time.Time { return time.Date(2024, 3, d, hour, 0, 0, 0, time.UTC) }
+
+	repo := NewRepository("/src/api")
+	repo.Contributors["Alice"] = &ContributorStats{Name: "Alice"}
+	repo.Credit("aaa", CommitCredit{Key: "Alice", Commits: 1, Time: day(1, 9)})
+	repo.Credit("bbb", CommitCredit{Key: "Alice", Commits: 1, Time: day(1, 18)})
+	repo.Credit("ccc", CommitCredit{Key: "Alice", Commits: 1, Time: day(5, 12)})
+	repo.Credit("ddd", CommitCredit{Key: "Alice", Commits: 1, Time: day(20, 12)})
+
+	alice := repo.Contributors["Alice"]
+	if !alice.FirstCommit.Equal(day(1, 9)) || !alice.LastCommit.Equal(day(20, 12)) {
+		t.Errorf("Unexpected commit range: %v to %v", alice.FirstCommit, alice.LastCommit)
+	}
+	if alice.ActiveDays != 3 || alice.TenureDays != 20 || alice.LongestGapDays != 14 {
+		t.Errorf("Expected 3 active days over 20 with a 14 day gap, got %d, %d and %d", alice.ActiveDays, alice.TenureDays, alice.LongestGapDays)
+	}
+
+	// A collapsed cherry-pick no longer counts as activity
+	repo.Collapse("ddd")
+	if !alice.LastCommit.Equal(day(5, 12)) || alice.ActiveDays != 2 || alice.LongestGapDays != 3 {
+		t.Errorf("Expected the collapsed commit to be forgotten, got last %v, %d active days, gap %d", alice.LastCommit, alice.ActiveDays, alice.LongestGapDays)
+	}
+
+	other := &ContributorStats{Name: "A. Smith"}
+	other.addCredit(CommitCredit{Commits: 1, Time: day(3, 12)}, 1)
+	alice.Merge(other)
+	if alice.ActiveDays != 3 || alice.LongestGapDays != 1 {
+		t.Errorf("Expected merged activity, got %d active days and gap %d", alice.ActiveDays, alice.LongestGapDays)
+	}
+
+	clone := alice.Clone()
+	clone.addCredit(CommitCredit{Commits: 1, Time: day(30, 12)}, 1)
+	if alice.ActiveDays != 3 || clone.ActiveDays != 4 {
+		t.Errorf("Expected the clone to be independent, got %d and %d active days", alice.ActiveDays, clone.ActiveDays)
+	}
+}
+
+func TestGlobalStats_RemoveInactive(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	api := NewRepository("/src/api")
+	api.Contributors["Alice"] = &ContributorStats{Name: "Alice"}
+	api.Contributors["Bob"] = &ContributorStats{Name: "Bob"}
+	api.Credit("aaa", CommitCredit{Key: "Alice", Commits: 1, Time: now.AddDate(0, 0, -3)})
+	api.Credit("bbb", CommitCredit{Key: "Bob", Commits: 1, Time: now.AddDate(-1, 0, 0)})
+
+	web := NewRepository("/src/web")
+	web.Contributors["Alice"] = &ContributorStats{Name: "Alice"}
+	web.Credit("ccc", CommitCredit{Key: "Alice", Commits: 1, Time: now.AddDate(0, -6, 0)})
+
+	gs := NewGlobalStats()
+	gs.AddRepository(api)
+	gs.AddRepository(web)
+
+	if removed := gs.RemoveInactive(now.AddDate(0, 0, -30)); removed != 1 {
+		t.Errorf("Expected 1 inactive contributor, got %d", removed)
+	}
+	if _, ok := gs.Contributors["Alice"]; !ok {
+		t.Error("Expected Alice to remain globally")
+	}
+	if _, ok := gs.Contributors["Bob"]; ok {
+		t.Error("Expected Bob to be removed")
+	}
+	if _, ok := web.Contributors["Alice"]; ok {
+		t.Error("Expected Alice to be removed from the repository Alice left")
+	}
+
+	sorted := gs.GetSortedContributors("last-commit", 0)
+	if len(sorted) != 1 || sorted[0].Name != "Alice" {
+		t.Errorf("Unexpected contributors: %+v", sorted)
+	}
+}
+
+func TestSortContributors_Dates(t *testing.T) {
+	early := &ContributorStats{Name: "Early", FirstCommit: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), LastCommit: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), ActiveDays: 50}
+	late := &ContributorStats{Name: "Late", FirstCommit: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), LastCommit: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), ActiveDays: 80}
+	undated := &ContributorStats{Name: "Undated"}
+	stats := map[string]*ContributorStats{"early": early, "late": late, "undated": undated}
+
+	tests := []struct {
+		sortBy string
+		want   []string
+	}{
+		{"first-commit", []string{"Early", "Late", "Undated"}},
+		{"last-commit", []string{"Late", "Early", "Undated"}},
+		{"active-days", []string{"Late", "Early", "Undated"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sortBy, func(t *testing.T) {
+			sorted := sortContributors(stats, tt.sortBy, 0)
+			for i, name := range tt.want {
+				if sorted[i].Name != name {
+					t.Errorf("Position %d: got %s, want %s", i, sorted[i].Name, name)
+				}
+			}
+		})
+	}
+}
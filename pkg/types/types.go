@@ -2,6 +2,7 @@ package types
 
 import (
 	"sort"
+	"time"
 )
 
 const (
@@ -12,26 +13,272 @@ const (
 
 // Repository represents a Git repository with its contributor statistics
 type Repository struct {
-	Path         string
-	Name         string
-	Contributors map[string]*ContributorStats
+	Path         string                       `json:"path"`
+	Name         string                       `json:"name"`
+	Contributors map[string]*ContributorStats `json:"contributors"`
+	// Head is the commit HEAD pointed to when analyzed, empty for a repository without commits
+	Head string `json:"head,omitempty"`
+	// Revision is the revision spec the history was selected with, and Refs
+	// the refs it resolved to
+	Revision string        `json:"revision,omitempty"`
+	Refs     []ResolvedRef `json:"refs,omitempty"`
+	// RootCommits are the parentless commits of the history, shared by forks and clones
+	RootCommits []string `json:"root_commits,omitempty"`
+	// SurvivingLines counts the lines of the text files at HEAD that were
+	// blamed for ownership, including those of contributors removed later
+	SurvivingLines int `json:"surviving_lines,omitempty"`
+	// FilteredLines counts the changed lines left out of line statistics, by
+	// the path filter that removed them
+	FilteredLines map[string]int `json:"filtered_lines,omitempty"`
+	// Commits maps each analyzed commit hash to what it credited to
+	// contributors. GlobalStats.AddRepository releases it once it has
+	// subtracted the commits other repositories already counted.
+	Commits map[string][]CommitCredit `json:"-"`
+	// Files maps the files at HEAD to how much each contributor key wrote of
+	// them; filled when concentration measures are requested
+	Files map[string]map[string]int `json:"-"`
 }
 
-// ContributorStats holds statistics for a single contributor
-type ContributorStats struct {
-	Name         string
-	Email        string
-	CommitCount  int
+// ResolvedRef is a ref or revision and the commit it pointed to when analyzed
+type ResolvedRef struct {
+	Ref    string `json:"ref"`
+	Commit string `json:"commit"`
+}
+
+// CommitCredit is what one commit contributed to one contributor, stored
+// under the contributor's key in the repository
+type CommitCredit struct {
+	Key          string
+	Commits      int
 	LinesAdded   int
 	LinesDeleted int
-	LinesChanged int
-	Aliases      []string
+	CoAuthored   int
+	Automated    int
+	Collapsed    int
+	// BinaryFiles counts the binary files changed, which have no line
+	// counts; BinaryBytesAdded and BinaryBytesDeleted are by how many bytes
+	// they grew and shrank when sizes are measured
+	BinaryFiles        int
+	BinaryBytesAdded   int
+	BinaryBytesDeleted int
+	// Languages splits LinesAdded and LinesDeleted by the language of the
+	// files changed
+	Languages []LanguageCredit
+	// Filtered lists the changed lines path filters left out of the commit;
+	// only one credit per commit carries them, as they belong to no contributor
+	Filtered []FilteredCredit
+	// Time is the author date of the commit; zero when unknown
+	Time time.Time
+	// Bucket is the start of the time bucket the commit falls in; zero when
+	// no activity series is collected
+	Bucket time.Time
+}
+
+// FilteredCredit is how many changed lines of a commit one path filter left out
+type FilteredCredit struct {
+	Filter string
+	Lines  int
+}
+
+// Credit applies the credits of the commit hash to the repository's
+// contributors, which must already exist, and remembers them so GlobalStats
+// can count a commit shared by several repositories only once
+func (r *Repository) Credit(hash string, credits ...CommitCredit) {
+	if r.Commits == nil {
+		r.Commits = make(map[string][]CommitCredit)
+	}
+	for _, credit := range credits {
+		r.Contributors[credit.Key].addCredit(credit, 1)
+		r.FilteredLines = addFiltered(r.FilteredLines, credit.Filtered, 1)
+	}
+	r.Commits[hash] = append(r.Commits[hash], credits...)
+}
+
+// addFiltered adds filtered lines to totals, or removes them when sign is
+// -1, and returns totals, which is allocated on first use; filters left
+// without lines are dropped
+func addFiltered(totals map[string]int, filtered []FilteredCredit, sign int) map[string]int {
+	if len(filtered) == 0 {
+		return totals
+	}
+	if totals == nil {
+		totals = make(map[string]int)
+	}
+	for _, credit := range filtered {
+		totals[credit.Filter] += sign * credit.Lines
+		if totals[credit.Filter] <= 0 {
+			delete(totals, credit.Filter)
+		}
+	}
+	return totals
+}
+
+// Collapse takes a commit that duplicates another one, such as a cherry-pick,
+// back out of the counters. Each contributor it credited with the commit
+// records it as collapsed instead. It reports whether hash was credited.
+func (r *Repository) Collapse(hash string) bool {
+	credits, ok := r.Commits[hash]
+	if !ok {
+		return false
+	}
+
+	collapsed := make([]CommitCredit, 0, len(credits))
+	for _, credit := range credits {
+		stats := r.Contributors[credit.Key]
+		stats.addCredit(credit, -1)
+		r.FilteredLines = addFiltered(r.FilteredLines, credit.Filtered, -1)
+		replacement := CommitCredit{Key: credit.Key, Collapsed: credit.Collapsed + credit.Commits}
+		stats.addCredit(replacement, 1)
+		collapsed = append(collapsed, replacement)
+	}
+	r.Commits[hash] = collapsed
+	return true
+}
+
+// ContributorStats holds statistics for a single contributor
+type ContributorStats struct {
+	Name string `json:"name"`
+	// Email is the first address seen for the contributor, Emails lists every address
+	Email        string   `json:"email"`
+	Emails       []string `json:"emails,omitempty"`
+	CommitCount  int      `json:"commits"`
+	LinesAdded   int      `json:"lines_added"`
+	LinesDeleted int      `json:"lines_deleted"`
+	LinesChanged int      `json:"lines_changed"`
+	// Languages splits the line counts by the language of the files changed
+	Languages map[string]LanguageLines `json:"languages,omitempty"`
+	// BinaryFiles counts changes to binary files such as images and other
+	// assets; the byte counts are their growth and shrinkage when measured
+	BinaryFiles        int `json:"binary_files"`
+	BinaryBytesAdded   int `json:"binary_bytes_added,omitempty"`
+	BinaryBytesDeleted int `json:"binary_bytes_deleted,omitempty"`
+	// OwnedLines counts the lines at HEAD that git blame attributes to the contributor
+	OwnedLines int `json:"owned_lines"`
+	// CoAuthoredCommits counts commits crediting the contributor in a Co-authored-by trailer
+	CoAuthoredCommits int      `json:"co_authored_commits"`
+	Aliases           []string `json:"aliases,omitempty"`
+	// Identities lists every distinct name/email pair the contributor committed with
+	Identities []Identity `json:"identities,omitempty"`
+	// Merges records contributors folded into this one by identity resolution
+	Merges []IdentityMerge `json:"merges,omitempty"`
+	// CollapsedCommits counts duplicates of other commits, such as cherry-picks,
+	// that were left out of the counters
+	CollapsedCommits int `json:"collapsed_commits"`
+	// AutomatedCommits counts commits whose subject looks machine-generated
+	AutomatedCommits int `json:"automated_commits"`
+	// IsBot marks automation accounts such as dependabot or CI release bots
+	IsBot bool `json:"is_bot"`
+	// FirstCommit and LastCommit are the author dates of the earliest and
+	// latest credited commits
+	FirstCommit time.Time `json:"first_commit,omitzero"`
+	LastCommit  time.Time `json:"last_commit,omitzero"`
+	// ActiveDays counts the distinct UTC days with a credited commit,
+	// TenureDays the days from the first to the last of them, and
+	// LongestGapDays the most days without a commit between two active days
+	ActiveDays     int `json:"active_days"`
+	TenureDays     int `json:"tenure_days"`
+	LongestGapDays int `json:"longest_gap_days"`
+	// Activity holds the contributor's work per time bucket, keyed by bucket
+	// start, when an activity interval is configured
+	Activity map[time.Time]*Activity `json:"-"`
+
+	// commitTimes and activeDays count credited commits per author time in
+	// Unix seconds and per UTC day number
+	commitTimes map[int64]int
+	activeDays  map[int64]int
+}
+
+// IdentityMerge describes one contributor merged into another and why
+type IdentityMerge struct {
+	Name string `json:"name"`
+	// Confidence is the similarity score in [0, 1] that justified the merge
+	Confidence float64 `json:"confidence"`
+	Reason     string  `json:"reason"`
+}
+
+// Identity is a name and email pair as recorded in commits
+type Identity struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// addCredit adds a commit credit to the counters, or removes it when sign is -1
+func (cs *ContributorStats) addCredit(credit CommitCredit, sign int) {
+	cs.CommitCount += sign * credit.Commits
+	cs.LinesAdded += sign * credit.LinesAdded
+	cs.LinesDeleted += sign * credit.LinesDeleted
+	cs.LinesChanged += sign * (credit.LinesAdded + credit.LinesDeleted)
+	cs.BinaryFiles += sign * credit.BinaryFiles
+	cs.BinaryBytesAdded += sign * credit.BinaryBytesAdded
+	cs.BinaryBytesDeleted += sign * credit.BinaryBytesDeleted
+	cs.CoAuthoredCommits += sign * credit.CoAuthored
+	cs.AutomatedCommits += sign * credit.Automated
+	cs.CollapsedCommits += sign * credit.Collapsed
+	cs.addLanguages(credit.Languages, sign)
+	cs.addActivity(credit, sign)
+	// Co-authoring a commit counts as being active, even under policies that
+	// leave the commit itself to the author
+	if credit.Commits > 0 || credit.CoAuthored > 0 {
+		cs.addCommitTime(credit.Time, sign)
+	}
+}
+
+// AddIdentity records a name/email pair for the contributor, ignoring duplicates
+func (cs *ContributorStats) AddIdentity(name, email string) {
+	identity := Identity{Name: name, Email: email}
+	for _, existing := range cs.Identities {
+		if existing == identity {
+			return
+		}
+	}
+	cs.Identities = append(cs.Identities, identity)
+}
+
+// AddAlias records an alternative name for the contributor, ignoring duplicates
+func (cs *ContributorStats) AddAlias(alias string) {
+	if alias == "" || alias == cs.Name {
+		return
+	}
+	cs.Aliases = appendUnique(cs.Aliases, alias)
+}
+
+// AddEmail records an email address for the contributor, ignoring duplicates.
+// The first address added becomes the primary Email.
+func (cs *ContributorStats) AddEmail(email string) {
+	if email == "" {
+		return
+	}
+	if cs.Email == "" {
+		cs.Email = email
+	}
+	if len(cs.Emails) == 0 && cs.Email != email {
+		cs.Emails = append(cs.Emails, cs.Email)
+	}
+	cs.Emails = appendUnique(cs.Emails, email)
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
 }
 
 // GlobalStats aggregates contributor statistics across multiple repositories
 type GlobalStats struct {
 	Contributors map[string]*ContributorStats
 	Repositories []*Repository
+	// DuplicateCommits counts commits seen in more than one repository and
+	// therefore left out of the global totals after their first occurrence
+	DuplicateCommits int
+
+	commits map[string]struct{}
+	// filtered counts the lines path filters left out, by filter
+	filtered map[string]int
+	// heads maps the HEAD commits of added repositories to their surviving lines
+	heads map[string]int
 }
 
 // NewGlobalStats creates a new GlobalStats instance
@@ -39,54 +286,272 @@ func NewGlobalStats() *GlobalStats {
 	return &GlobalStats{
 		Contributors: make(map[string]*ContributorStats),
 		Repositories: make([]*Repository, 0),
+		commits:      make(map[string]struct{}),
+		heads:        make(map[string]int),
 	}
 }
 
-// AddRepository adds a repository's statistics to the global stats
+// AddRepository adds a repository's statistics to the global stats. Commits
+// already added through another repository, such as a fork or a second
+// clone, are counted only once globally, and so are the owned lines of a
+// repository checked out at a HEAD that was already added. The repository's
+// own numbers are left untouched, but its commit ledger is released, since
+// only the hashes are needed to recognise later duplicates.
 func (gs *GlobalStats) AddRepository(repo *Repository) {
 	gs.Repositories = append(gs.Repositories, repo)
 
 	for name, stats := range repo.Contributors {
 		if existing, exists := gs.Contributors[name]; exists {
-			existing.CommitCount += stats.CommitCount
-			existing.LinesAdded += stats.LinesAdded
-			existing.LinesDeleted += stats.LinesDeleted
-			existing.LinesChanged += stats.LinesChanged
-			// Merge aliases, avoiding duplicates
-			for _, alias := range stats.Aliases {
-				found := false
-				for _, existingAlias := range existing.Aliases {
-					if existingAlias == alias {
-						found = true
-						break
-					}
-				}
-				if !found {
-					existing.Aliases = append(existing.Aliases, alias)
-				}
+			existing.Merge(stats)
+		} else {
+			// Copy so later merges don't modify the repository's stats
+			gs.Contributors[name] = stats.Clone()
+		}
+	}
+
+	if gs.commits == nil {
+		gs.commits = make(map[string]struct{})
+	}
+	for filter, lines := range repo.FilteredLines {
+		gs.filtered = addFiltered(gs.filtered, []FilteredCredit{{Filter: filter, Lines: lines}}, 1)
+	}
+	for hash, credits := range repo.Commits {
+		if _, seen := gs.commits[hash]; !seen {
+			gs.commits[hash] = struct{}{}
+			continue
+		}
+		for _, credit := range credits {
+			if stats, ok := gs.Contributors[credit.Key]; ok {
+				stats.addCredit(credit, -1)
+			}
+			gs.filtered = addFiltered(gs.filtered, credit.Filtered, -1)
+		}
+		gs.DuplicateCommits++
+	}
+	repo.Commits = nil
+
+	// A second checkout of the same HEAD has the same owners; count them once
+	if repo.Head == "" {
+		return
+	}
+	if gs.heads == nil {
+		gs.heads = make(map[string]int)
+	}
+	if _, seen := gs.heads[repo.Head]; !seen {
+		gs.heads[repo.Head] = repo.SurvivingLines
+		return
+	}
+	for key, stats := range repo.Contributors {
+		if global, ok := gs.Contributors[key]; ok {
+			global.OwnedLines -= stats.OwnedLines
+		}
+	}
+}
+
+// FilteredLines returns the changed lines path filters left out of every
+// repository, by filter, counting commits shared by several repositories once
+func (gs *GlobalStats) FilteredLines() map[string]int {
+	filtered := make(map[string]int, len(gs.filtered))
+	for filter, lines := range gs.filtered {
+		filtered[filter] = lines
+	}
+	return filtered
+}
+
+// SurvivingLines returns the lines blamed for ownership across all
+// repositories, counting repositories checked out at the same commit once
+func (gs *GlobalStats) SurvivingLines() int {
+	total := 0
+	for _, lines := range gs.heads {
+		total += lines
+	}
+	return total
+}
+
+// UniqueCommits returns the number of distinct commits across all repositories
+func (gs *GlobalStats) UniqueCommits() int {
+	return len(gs.commits)
+}
+
+// SharedHistory groups repositories that have a root commit in common, such
+// as a fork and its upstream or two clones of one project. Only groups of two
+// or more repositories are returned, in scan order.
+func (gs *GlobalStats) SharedHistory() [][]*Repository {
+	parent := make([]int, len(gs.Repositories))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	owners := make(map[string]int)
+	for i, repo := range gs.Repositories {
+		for _, root := range repo.RootCommits {
+			owner, ok := owners[root]
+			if !ok {
+				owners[root] = i
+				continue
+			}
+			if a, b := find(owner), find(i); a != b {
+				parent[max(a, b)] = min(a, b)
 			}
+		}
+	}
+
+	index := make(map[int]int)
+	groups := make([][]*Repository, 0)
+	for i, repo := range gs.Repositories {
+		root := find(i)
+		g, ok := index[root]
+		if !ok {
+			g = len(groups)
+			index[root] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], repo)
+	}
+
+	shared := make([][]*Repository, 0)
+	for _, group := range groups {
+		if len(group) > 1 {
+			shared = append(shared, group)
+		}
+	}
+	return shared
+}
+
+// MergeContributors folds the contributor stored under sourceKey into the one
+// under targetKey, both globally and within every repository. The source's
+// names and emails are kept as aliases of the target.
+func (gs *GlobalStats) MergeContributors(targetKey, sourceKey string) {
+	if targetKey == sourceKey {
+		return
+	}
+
+	if source, ok := gs.Contributors[sourceKey]; ok {
+		if target, exists := gs.Contributors[targetKey]; exists {
+			target.Merge(source)
 		} else {
-			// Create a copy of aliases slice
-			aliases := make([]string, len(stats.Aliases))
-			copy(aliases, stats.Aliases)
-			gs.Contributors[name] = &ContributorStats{
-				Name:         stats.Name,
-				Email:        stats.Email,
-				CommitCount:  stats.CommitCount,
-				LinesAdded:   stats.LinesAdded,
-				LinesDeleted: stats.LinesDeleted,
-				LinesChanged: stats.LinesChanged,
-				Aliases:      aliases,
+			gs.Contributors[targetKey] = source
+		}
+		delete(gs.Contributors, sourceKey)
+	}
+
+	for _, repo := range gs.Repositories {
+		source, ok := repo.Contributors[sourceKey]
+		if !ok {
+			continue
+		}
+		if target, exists := repo.Contributors[targetKey]; exists {
+			target.Merge(source)
+		} else {
+			repo.Contributors[targetKey] = source
+		}
+		delete(repo.Contributors, sourceKey)
+
+		for _, weights := range repo.Files {
+			if weight, ok := weights[sourceKey]; ok {
+				weights[targetKey] += weight
+				delete(weights, sourceKey)
+			}
+		}
+	}
+}
+
+// RemoveBots drops every contributor marked as a bot, both globally and
+// within every repository, and returns how many were removed
+func (gs *GlobalStats) RemoveBots() int {
+	removed := 0
+	for key, stats := range gs.Contributors {
+		if stats.IsBot {
+			delete(gs.Contributors, key)
+			removed++
+		}
+	}
+	for _, repo := range gs.Repositories {
+		for key, stats := range repo.Contributors {
+			if stats.IsBot {
+				delete(repo.Contributors, key)
 			}
 		}
 	}
+	return removed
+}
+
+// Merge adds other's counters to cs and records other's names, emails and
+// identities, avoiding duplicates
+func (cs *ContributorStats) Merge(other *ContributorStats) {
+	cs.CommitCount += other.CommitCount
+	cs.LinesAdded += other.LinesAdded
+	cs.LinesDeleted += other.LinesDeleted
+	cs.LinesChanged += other.LinesChanged
+	cs.BinaryFiles += other.BinaryFiles
+	cs.BinaryBytesAdded += other.BinaryBytesAdded
+	cs.BinaryBytesDeleted += other.BinaryBytesDeleted
+	cs.OwnedLines += other.OwnedLines
+	cs.CoAuthoredCommits += other.CoAuthoredCommits
+	cs.AutomatedCommits += other.AutomatedCommits
+	cs.CollapsedCommits += other.CollapsedCommits
+	cs.IsBot = cs.IsBot || other.IsBot
+	cs.mergeCommitTimes(other)
+	cs.mergeLanguages(other.Languages)
+	if len(other.Activity) > 0 {
+		if cs.Activity == nil {
+			cs.Activity = make(map[time.Time]*Activity)
+		}
+		mergeActivity(cs.Activity, other.Activity)
+	}
+
+	cs.AddAlias(other.Name)
+	for _, alias := range other.Aliases {
+		cs.AddAlias(alias)
+	}
+	cs.AddEmail(other.Email)
+	for _, email := range other.Emails {
+		cs.AddEmail(email)
+	}
+	for _, identity := range other.Identities {
+		cs.AddIdentity(identity.Name, identity.Email)
+	}
+	cs.Merges = append(cs.Merges, other.Merges...)
+}
+
+// Clone returns a deep copy of cs
+func (cs *ContributorStats) Clone() *ContributorStats {
+	clone := *cs
+	clone.Aliases = append(make([]string, 0, len(cs.Aliases)), cs.Aliases...)
+	clone.Emails = append(make([]string, 0, len(cs.Emails)), cs.Emails...)
+	clone.Identities = append(make([]Identity, 0, len(cs.Identities)), cs.Identities...)
+	clone.Merges = append(make([]IdentityMerge, 0, len(cs.Merges)), cs.Merges...)
+	clone.commitTimes, clone.activeDays = cs.cloneCommitTimes()
+	clone.Languages = nil
+	clone.mergeLanguages(cs.Languages)
+	if cs.Activity != nil {
+		clone.Activity = make(map[time.Time]*Activity, len(cs.Activity))
+		mergeActivity(clone.Activity, cs.Activity)
+	}
+	return &clone
 }
 
 // GetSortedContributors returns contributors sorted by the specified criteria
 func (gs *GlobalStats) GetSortedContributors(sortBy string, topN int) []*ContributorStats {
-	contributors := make([]*ContributorStats, 0, len(gs.Contributors))
-	for _, stats := range gs.Contributors {
-		contributors = append(contributors, stats)
+	return sortContributors(gs.Contributors, sortBy, topN)
+}
+
+// GetSortedContributors returns the repository's contributors sorted by the specified criteria
+func (r *Repository) GetSortedContributors(sortBy string, topN int) []*ContributorStats {
+	return sortContributors(r.Contributors, sortBy, topN)
+}
+
+func sortContributors(stats map[string]*ContributorStats, sortBy string, topN int) []*ContributorStats {
+	contributors := make([]*ContributorStats, 0, len(stats))
+	for _, contributor := range stats {
+		contributors = append(contributors, contributor)
 	}
 
 	switch sortBy {
@@ -104,6 +569,35 @@ func (gs *GlobalStats) GetSortedContributors(sortBy string, topN int) []*Contrib
 			scoreJ := contributors[j].CommitCount*commitsWeight + contributors[j].LinesChanged/linesWeight
 			return scoreI > scoreJ
 		})
+	case "first-commit":
+		// Longest-standing contributors first; those without dates last
+		sort.Slice(contributors, func(i, j int) bool {
+			return earlier(contributors[i].FirstCommit, contributors[j].FirstCommit)
+		})
+	case "last-commit":
+		sort.Slice(contributors, func(i, j int) bool {
+			return contributors[i].LastCommit.After(contributors[j].LastCommit)
+		})
+	case "ownership":
+		sort.Slice(contributors, func(i, j int) bool {
+			return contributors[i].OwnedLines > contributors[j].OwnedLines
+		})
+	case "binary":
+		sort.Slice(contributors, func(i, j int) bool {
+			return contributors[i].BinaryFiles > contributors[j].BinaryFiles
+		})
+	case "active-days":
+		sort.Slice(contributors, func(i, j int) bool {
+			return contributors[i].ActiveDays > contributors[j].ActiveDays
+		})
+	case "tenure":
+		sort.Slice(contributors, func(i, j int) bool {
+			return contributors[i].TenureDays > contributors[j].TenureDays
+		})
+	case "longest-gap":
+		sort.Slice(contributors, func(i, j int) bool {
+			return contributors[i].LongestGapDays > contributors[j].LongestGapDays
+		})
 	default:
 		sort.Slice(contributors, func(i, j int) bool {
 			return contributors[i].CommitCount > contributors[j].CommitCount
@@ -117,12 +611,21 @@ func (gs *GlobalStats) GetSortedContributors(sortBy string, topN int) []*Contrib
 	return contributors
 }
 
+// earlier orders times ascending with zero times last
+func earlier(a, b time.Time) bool {
+	if a.IsZero() || b.IsZero() {
+		return !a.IsZero() && b.IsZero()
+	}
+	return a.Before(b)
+}
+
 // NewRepository creates a new Repository instance for the given path
 func NewRepository(path string) *Repository {
 	return &Repository{
 		Path:         path,
 		Name:         extractRepoName(path),
 		Contributors: make(map[string]*ContributorStats),
+		Commits:      make(map[string][]CommitCredit),
 	}
 }
 
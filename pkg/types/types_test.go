@@ -110,6 +110,7 @@ func TestGlobalStats_GetSortedContributors(t *testing.T) {
 		Name:         "bob",
 		CommitCount:  5,
 		LinesChanged: 200,
+		BinaryFiles:  7,
 	}
 	gs.Contributors["charlie"] = &ContributorStats{
 		Name:         "charlie",
@@ -134,6 +135,13 @@ func TestGlobalStats_GetSortedContributors(t *testing.T) {
 		}
 	})
 
+	t.Run("sort by binary files", func(t *testing.T) {
+		sorted := gs.GetSortedContributors("binary", 0)
+		if sorted[0].Name != "bob" || sorted[0].BinaryFiles != 7 {
+			t.Errorf("Expected bob first with 7 binary files, got %s with %d", sorted[0].Name, sorted[0].BinaryFiles)
+		}
+	})
+
 	t.Run("limit results", func(t *testing.T) {
 		sorted := gs.GetSortedContributors("commits", 2)
 		if len(sorted) != 2 {
@@ -141,3 +149,197 @@ func TestGlobalStats_GetSortedContributors(t *testing.T) {
 		}
 	})
 }
+
+func TestGlobalStats_AddRepositoryMergesEmails(t *testing.T) {
+	gs := NewGlobalStats()
+
+	repo1 := NewRepository("/repo1")
+	repo1.Contributors["alice"] = &ContributorStats{
+		Name:        "Alice",
+		Email:       "alice@example.com",
+		Emails:      []string{"alice@example.com"},
+		CommitCount: 1,
+	}
+	gs.AddRepository(repo1)
+
+	repo2 := NewRepository("/repo2")
+	repo2.Contributors["alice"] = &ContributorStats{
+		Name:        "alice",
+		Email:       "alice@home.net",
+		Emails:      []string{"alice@home.net", "alice@example.com"},
+		CommitCount: 1,
+	}
+	gs.AddRepository(repo2)
+
+	alice := gs.Contributors["alice"]
+	if alice.Email != "alice@example.com" {
+		t.Errorf("Expected primary email from first repository, got %s", alice.Email)
+	}
+	if len(alice.Emails) != 2 {
+		t.Errorf("Expected 2 distinct emails, got %v", alice.Emails)
+	}
+	if len(alice.Aliases) != 1 || alice.Aliases[0] != "alice" {
+		t.Errorf("Expected differing display name recorded as alias, got %v", alice.Aliases)
+	}
+
+	repo1.Contributors["alice"].Emails[0] = "changed@example.com"
+	if alice.Emails[0] != "alice@example.com" {
+		t.Error("Global stats should not share the repository's email slice")
+	}
+}
+
+func TestGlobalStats_MergeContributors(t *testing.T) {
+	gs := NewGlobalStats()
+
+	repo1 := NewRepository("/repo1")
+	repo1.Contributors["a"] = &ContributorStats{Name: "Alice", Email: "alice@example.com", CommitCount: 5, LinesChanged: 50}
+	repo1.Contributors["b"] = &ContributorStats{Name: "A. Liddell", Email: "al@example.com", CommitCount: 2, LinesChanged: 20}
+	gs.AddRepository(repo1)
+
+	repo2 := NewRepository("/repo2")
+	repo2.Contributors["b"] = &ContributorStats{Name: "A. Liddell", CommitCount: 1, LinesChanged: 10}
+	gs.AddRepository(repo2)
+
+	gs.MergeContributors("a", "b")
+
+	if len(gs.Contributors) != 1 {
+		t.Fatalf("Expected 1 contributor after merge, got %d", len(gs.Contributors))
+	}
+	alice := gs.Contributors["a"]
+	if alice.CommitCount != 8 || alice.LinesChanged != 80 {
+		t.Errorf("Merged stats incorrect: commits=%d, lines=%d", alice.CommitCount, alice.LinesChanged)
+	}
+	if len(alice.Aliases) != 1 || alice.Aliases[0] != "A. Liddell" {
+		t.Errorf("Expected merged name as alias, got %v", alice.Aliases)
+	}
+	if len(alice.Emails) != 2 {
+		t.Errorf("Expected both emails, got %v", alice.Emails)
+	}
+
+	if repo1.Contributors["a"].CommitCount != 7 || repo1.Contributors["b"] != nil {
+		t.Errorf("Expected repo1 entries merged, got %+v", repo1.Contributors)
+	}
+	if repo2.Contributors["a"] == nil || repo2.Contributors["a"].CommitCount != 1 {
+		t.Errorf("Expected repo2 entry re-keyed, got %+v", repo2.Contributors)
+	}
+}
+
+func TestGlobalStats_RemoveBots(t *testing.T) {
+	repo := NewRepository("/path/to/repo")
+	repo.Contributors["alice"] = &ContributorStats{Name: "Alice", CommitCount: 3}
+	repo.Contributors["ci"] = &ContributorStats{Name: "CI", CommitCount: 40, IsBot: true}
+
+	gs := NewGlobalStats()
+	gs.AddRepository(repo)
+
+	if removed := gs.RemoveBots(); removed != 1 {
+		t.Errorf("Expected 1 bot removed, got %d", removed)
+	}
+	if _, ok := gs.Contributors["ci"]; ok {
+		t.Error("Expected bot to be removed globally")
+	}
+	if _, ok := repo.Contributors["ci"]; ok {
+		t.Error("Expected bot to be removed from the repository")
+	}
+	if gs.Contributors["alice"] == nil {
+		t.Error("Expected human contributor to remain")
+	}
+}
+
+func TestGlobalStats_AddRepositorySkipsSharedCommits(t *testing.T) {
+	newRepo := func(path string, hashes ...string) *Repository {
+		repo := NewRepository(path)
+		repo.RootCommits = []string{"root"}
+		repo.Contributors["alice"] = &ContributorStats{Name: "Alice"}
+		for _, hash := range hashes {
+			repo.Credit(hash, CommitCredit{Key: "alice", Commits: 1, LinesAdded: 10, LinesDeleted: 2, BinaryFiles: 1, BinaryBytesAdded: 100,
+				Filtered: []FilteredCredit{{Filter: "vendor/", Lines: 5}}})
+		}
+		return repo
+	}
+
+	upstream := newRepo("/src/upstream", "root", "a")
+	fork := newRepo("/src/fork", "root", "a", "b")
+	other := NewRepository("/src/other")
+	other.RootCommits = []string{"elsewhere"}
+
+	gs := NewGlobalStats()
+	gs.AddRepository(upstream)
+	gs.AddRepository(other)
+	gs.AddRepository(fork)
+
+	alice := gs.Contributors["alice"]
+	if alice.CommitCount != 3 || alice.LinesAdded != 30 || alice.LinesChanged != 36 || alice.BinaryFiles != 3 || alice.BinaryBytesAdded != 300 {
+		t.Errorf("Expected shared commits counted once globally, got %+v", alice)
+	}
+	if fork.Contributors["alice"].CommitCount != 3 {
+		t.Errorf("Expected per-repository numbers to be kept, got %d", fork.Contributors["alice"].CommitCount)
+	}
+	if gs.UniqueCommits() != 3 || gs.DuplicateCommits != 2 {
+		t.Errorf("Expected 3 unique and 2 duplicate commits, got %d and %d", gs.UniqueCommits(), gs.DuplicateCommits)
+	}
+	if filtered := gs.FilteredLines(); filtered["vendor/"] != 15 || fork.FilteredLines["vendor/"] != 15 {
+		t.Errorf("Expected shared filtered lines counted once globally, got %v and %v", filtered, fork.FilteredLines)
+	}
+	if upstream.Commits != nil || fork.Commits != nil {
+		t.Error("Expected commit ledgers to be released once merged")
+	}
+
+	shared := gs.SharedHistory()
+	if len(shared) != 1 || len(shared[0]) != 2 || shared[0][0] != upstream || shared[0][1] != fork {
+		t.Errorf("Expected upstream and fork to share history, got %v", shared)
+	}
+}
+
+func TestRepository_Collapse(t *testing.T) {
+	newRepo := func(path string) *Repository {
+		repo := NewRepository(path)
+		repo.Contributors["alice"] = &ContributorStats{Name: "Alice"}
+		repo.Credit("original", CommitCredit{Key: "alice", Commits: 1, LinesAdded: 5})
+		repo.Credit("pick", CommitCredit{Key: "alice", Commits: 1, LinesAdded: 5})
+		if !repo.Collapse("pick") || repo.Collapse("missing") {
+			t.Fatal("Expected only credited commits to be collapsed")
+		}
+		return repo
+	}
+
+	upstream := newRepo("/src/upstream")
+	alice := upstream.Contributors["alice"]
+	if alice.CommitCount != 1 || alice.LinesAdded != 5 || alice.CollapsedCommits != 1 {
+		t.Errorf("Expected the cherry-pick to be collapsed, got %+v", alice)
+	}
+
+	upstream.Collapse("pick")
+	if alice.CommitCount != 1 || alice.CollapsedCommits != 1 {
+		t.Errorf("Expected collapsing twice to be a no-op, got %+v", alice)
+	}
+
+	gs := NewGlobalStats()
+	gs.AddRepository(upstream)
+	gs.AddRepository(newRepo("/src/clone"))
+	if global := gs.Contributors["alice"]; global.CommitCount != 1 || global.CollapsedCommits != 1 {
+		t.Errorf("Expected collapsed commits shared by clones to count once, got %+v", global)
+	}
+}
+
+func TestGlobalStats_AddRepositoryCountsSameHeadOwnershipOnce(t *testing.T) {
+	newRepo := func(path, head string, owned int) *Repository {
+		repo := NewRepository(path)
+		repo.Head = head
+		repo.Contributors["Alice"] = &ContributorStats{Name: "Alice", OwnedLines: owned}
+		repo.SurvivingLines = owned
+		return repo
+	}
+
+	gs := NewGlobalStats()
+	gs.AddRepository(newRepo("/src/api", "aaa", 100))
+	gs.AddRepository(newRepo("/backup/api", "aaa", 100))
+	gs.AddRepository(newRepo("/src/web", "bbb", 50))
+
+	if owned := gs.Contributors["Alice"].OwnedLines; owned != 150 {
+		t.Errorf("Expected 150 owned lines, got %d", owned)
+	}
+	if total := gs.SurvivingLines(); total != 150 {
+		t.Errorf("Expected 150 surviving lines, got %d", total)
+	}
+}